@@ -5,12 +5,15 @@ import (
 	"api_chat_ws/cmd/route"
 	"api_chat_ws/internal/handler"
 	"api_chat_ws/internal/repository"
+	"api_chat_ws/internal/storage"
 	"api_chat_ws/internal/usecase"
+	"api_chat_ws/internal/worker"
 	"api_chat_ws/ws"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -28,18 +31,32 @@ func main() {
 	userRepo := repository.NewAuthRepo(db)
 	userUsecase := usecase.NewAuthUsecase(userRepo)
 	userHandler := handler.NewAuthHandler(userUsecase)
+	store, err := storage.FromEnv()
+	if err != nil {
+		log.Fatalf("storage : %v", err)
+	}
+
 	chatRepo := repository.NewChatRepository(db)
-	chatUsecase := usecase.NewChatUsecase(chatRepo)
+	chatUsecase := usecase.NewChatUsecase(chatRepo, usecase.LoadChatConfig(), store)
 
 	hub := ws.NewHub()
 	go hub.Run()
 
+	go worker.Run("sanction", time.Minute, worker.ExpireSanctions(chatUsecase, hub))
+	go worker.Run("retention", time.Hour, worker.PurgeRetention(chatUsecase))
+	go worker.Run("purge-group", time.Hour, worker.PurgeDeletedGroups(chatUsecase))
+	go worker.Run("attachment", time.Hour, worker.PurgeOrphanAttachments(chatUsecase))
+
 	ChatHandler := handler.NewChatHandler(
 		hub,
 		chatUsecase,
 	)
 
 	r := route.SetupRoute(userHandler, ChatHandler)
+	// file driver local dan memory disajikan langsung oleh server ini
+	if served, ok := store.(interface{ Handler() http.Handler }); ok {
+		r.PathPrefix(storage.MediaPrefix).Handler(served.Handler())
+	}
 
 	port := os.Getenv("PORT")
 	fmt.Println("server berjalan pada port:" + port)
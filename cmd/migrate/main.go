@@ -12,9 +12,20 @@ func main() {
 		log.Fatal(err)
 	}
 
-	if err := db.AutoMigrate(&model.User{}, &model.ChatGroup{}, &model.GroupMember{}, &model.Chat{}); err != nil {
+	if err := db.AutoMigrate(&model.User{}, &model.ChatGroup{}, &model.GroupMember{}, &model.Topic{}, &model.Chat{}, &model.ChatRead{}, &model.Reaction{}, &model.ChatRevision{}, &model.HiddenChat{}, &model.Mention{}, &model.Attachment{}, &model.GroupPermission{}, &model.GroupSanction{}, &model.GroupAudit{}); err != nil {
 		log.Fatalf("error migrasi : %v", err)
 	}
 
+	// grup lama dibuat dengan role "admin", jadikan admin paling awal sebagai owner
+	if err := db.Exec(`UPDATE group_members gm
+		JOIN (
+			SELECT MIN(id) AS id FROM group_members
+			WHERE role = ? AND group_id NOT IN (SELECT group_id FROM group_members WHERE role = ?)
+			GROUP BY group_id
+		) first_admin ON gm.id = first_admin.id
+		SET gm.role = ?`, model.RoleAdmin, model.RoleOwner, model.RoleOwner).Error; err != nil {
+		log.Fatalf("error migrasi owner : %v", err)
+	}
+
 	log.Println("Migrasi berhasil")
 }
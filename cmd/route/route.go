@@ -21,6 +21,10 @@ func SetupRoute(userHandler *handler.AuthHandler, ChatHandler *handler.WebSocket
 	chatM := r.PathPrefix("/chat").Subrouter()
 	chatM.Use(middleware.AuthMiddleware)
 	chatM.HandleFunc("/stream/{group_id}", ChatHandler.ServeWS)
+	chatM.HandleFunc("/inbox", ChatHandler.Inbox).Methods(http.MethodGet)
+	chatM.HandleFunc("/dm/{userId}", ChatHandler.OpenDirect).Methods(http.MethodPost)
+	chatM.HandleFunc("/search", ChatHandler.SearchChats).Methods(http.MethodGet)
+	chatM.HandleFunc("/capabilities", ChatHandler.Capabilities).Methods(http.MethodGet)
 
 	chatG := chatM.PathPrefix("/group").Subrouter()
 	chatG.HandleFunc("/create", ChatHandler.CreateGroup).Methods(http.MethodPost)
@@ -30,6 +34,39 @@ func SetupRoute(userHandler *handler.AuthHandler, ChatHandler *handler.WebSocket
 	chatG.HandleFunc("/remove-members/{groupId}", ChatHandler.RemoveMembers).Methods(http.MethodPost)
 	chatG.HandleFunc("/exit-group/{groupId}", ChatHandler.ExitGroup).Methods(http.MethodDelete)
 	chatG.HandleFunc("/update-role-members/{groupId}", ChatHandler.UpdateRoleMembers).Methods(http.MethodPut)
+	chatG.HandleFunc("/transfer-ownership/{groupId}", ChatHandler.TransferOwnership).Methods(http.MethodPut)
+	chatG.HandleFunc("/{groupId}/messages", ChatHandler.GetMessages).Methods(http.MethodGet)
+	chatG.HandleFunc("/{groupId}/messages/{chatId}/history", ChatHandler.GetEditHistory).Methods(http.MethodGet)
+	chatG.HandleFunc("/{groupId}/threads", ChatHandler.GetThreads).Methods(http.MethodGet)
+	chatG.HandleFunc("/{groupId}/threads/{chatId}", ChatHandler.GetThread).Methods(http.MethodGet)
+	chatG.HandleFunc("/{groupId}/pins", ChatHandler.GetPins).Methods(http.MethodGet)
+	chatG.HandleFunc("/{groupId}/pins/{chatId}", ChatHandler.PinChat).Methods(http.MethodPost)
+	chatG.HandleFunc("/{groupId}/pins/{chatId}", ChatHandler.UnpinChat).Methods(http.MethodDelete)
+	chatG.HandleFunc("/{groupId}/attachments", ChatHandler.UploadAttachment).Methods(http.MethodPost)
+	chatG.HandleFunc("/{groupId}/attachments/{attachmentId}", ChatHandler.GetAttachmentURL).Methods(http.MethodGet)
+	chatG.HandleFunc("/{groupId}/members", ChatHandler.GetMembers).Methods(http.MethodGet)
+	chatG.HandleFunc("/{groupId}/members/import", ChatHandler.ImportMembers).Methods(http.MethodPost)
+	chatG.HandleFunc("/{groupId}/nickname", ChatHandler.SetNickname).Methods(http.MethodPut)
+	chatG.HandleFunc("/{groupId}/avatar", ChatHandler.UploadAvatar).Methods(http.MethodPut)
+	chatG.HandleFunc("/{groupId}/avatar", ChatHandler.DeleteAvatar).Methods(http.MethodDelete)
+	chatG.HandleFunc("/{groupId}/banner", ChatHandler.UploadBanner).Methods(http.MethodPut)
+	chatG.HandleFunc("/{groupId}/banner", ChatHandler.DeleteBanner).Methods(http.MethodDelete)
+	chatG.HandleFunc("/{groupId}/topics", ChatHandler.GetTopics).Methods(http.MethodGet)
+	chatG.HandleFunc("/{groupId}/topics", ChatHandler.CreateTopic).Methods(http.MethodPost)
+	chatG.HandleFunc("/{groupId}/topics/{topicId}", ChatHandler.RenameTopic).Methods(http.MethodPut)
+	chatG.HandleFunc("/{groupId}/permissions", ChatHandler.GetPermissions).Methods(http.MethodGet)
+	chatG.HandleFunc("/{groupId}/permissions", ChatHandler.UpdatePermissions).Methods(http.MethodPut)
+	chatG.HandleFunc("/{groupId}/archive", ChatHandler.ArchiveGroup).Methods(http.MethodPost)
+	chatG.HandleFunc("/{groupId}/unarchive", ChatHandler.UnarchiveGroup).Methods(http.MethodPost)
+	chatG.HandleFunc("/{groupId}/restore", ChatHandler.RestoreGroup).Methods(http.MethodPost)
+	chatG.HandleFunc("/{groupId}/audit", ChatHandler.GetAudit).Methods(http.MethodGet)
+	chatG.HandleFunc("/{groupId}/retention", ChatHandler.GetRetention).Methods(http.MethodGet)
+	chatG.HandleFunc("/{groupId}/retention", ChatHandler.UpdateRetention).Methods(http.MethodPut)
+	chatG.HandleFunc("/{groupId}/sanctions", ChatHandler.GetSanctions).Methods(http.MethodGet)
+	chatG.HandleFunc("/{groupId}/mute", ChatHandler.Mute).Methods(http.MethodPost)
+	chatG.HandleFunc("/{groupId}/mute/{userId}", ChatHandler.Unmute).Methods(http.MethodDelete)
+	chatG.HandleFunc("/{groupId}/ban", ChatHandler.Ban).Methods(http.MethodPost)
+	chatG.HandleFunc("/{groupId}/ban/{userId}", ChatHandler.Unban).Methods(http.MethodDelete)
 
 	return r
 }
@@ -1,6 +1,10 @@
 package dto
 
-import "time"
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
 
 //auth
 type RegisterReq struct {
@@ -16,15 +20,27 @@ type LoginReq struct {
 
 //chat
 type IncomingMessage struct {
-	Action  string `json:"action"`
-	Content string `json:"content"`
-	ID      uint   `json:"id"`
+	Action        string `json:"action"`
+	Content       string `json:"content"`
+	ID            uint   `json:"id"`
+	TopicId       *uint  `json:"topic_id"`
+	ParentId      *uint  `json:"parent_id"`
+	ThreadId      *uint  `json:"thread_root_id"`
+	AlsoSend      bool   `json:"also_send"`
+	Emoji         string `json:"emoji"`
+	TargetGroupId uint   `json:"target_group_id"`
+	AttachmentIds []uint `json:"attachment_ids"`
 }
 
 type CreateChatReq struct {
-	GroupId  uint   `json:"group_id"`
-	MemberId uint   `json:"member_id"`
-	Message  string `json:"message"`
+	GroupId       uint   `json:"group_id"`
+	MemberId      uint   `json:"member_id"`
+	Message       string `json:"message"`
+	TopicId       *uint  `json:"topic_id"` // nil atau 0 berarti topic umum
+	ParentId      *uint  `json:"parent_id"`
+	ThreadId      *uint  `json:"thread_root_id"`
+	AlsoSend      bool   `json:"also_send"` // balasan thread juga dikirim ke timeline utama
+	AttachmentIds []uint `json:"attachment_ids"`
 }
 
 type UpdateChatReq struct {
@@ -34,11 +50,65 @@ type UpdateChatReq struct {
 }
 
 type ResponseChat struct {
-	ID        uint             `json:"chat_id"`
-	MemberId  uint             `json:"member_id"`
-	Message   string           `json:"message"`
-	CreatedAt time.Time        `json:"created_at"`
-	Status    []StatusChatRead `json:"status"`
+	ID            uint                 `json:"chat_id"`
+	MemberId      uint                 `json:"member_id"`
+	DisplayName   string               `json:"display_name"`
+	Type          string               `json:"type"`
+	Message       string               `json:"message"`
+	System        *SystemInfo          `json:"system,omitempty"`
+	TopicId       *uint                `json:"topic_id,omitempty"`
+	ReplyTo       *QuotedChat          `json:"reply_to,omitempty"`
+	ThreadId      *uint                `json:"thread_root_id,omitempty"`
+	AlsoSent      bool                 `json:"also_sent,omitempty"`
+	Thread        *ThreadInfo          `json:"thread,omitempty"`
+	Reactions     []ReactionCount      `json:"reactions,omitempty"`
+	CreatedAt     time.Time            `json:"created_at"`
+	EditedAt      *time.Time           `json:"edited_at,omitempty"`
+	Deleted       bool                 `json:"deleted,omitempty"`
+	Mentions      []MentionSpan        `json:"mentions,omitempty"`
+	MentionedMe   bool                 `json:"mentioned_me,omitempty"`
+	PinnedAt      *time.Time           `json:"pinned_at,omitempty"`
+	ForwardedFrom *ForwardInfo         `json:"forwarded_from,omitempty"`
+	Attachments   []AttachmentResponse `json:"attachments,omitempty"`
+	Status        []StatusChatRead     `json:"status"`
+}
+
+// cuplikan pesan yang dibalas, Deleted true jika pesannya sudah tidak ada
+type QuotedChat struct {
+	ChatId      uint   `json:"chat_id"`
+	MemberId    uint   `json:"member_id,omitempty"`
+	DisplayName string `json:"display_name,omitempty"`
+	Excerpt     string `json:"excerpt,omitempty"`
+	Deleted     bool   `json:"deleted"`
+}
+
+// ringkasan thread, hanya ada di pesan induk yang sudah punya balasan
+type ThreadInfo struct {
+	ReplyCount   int        `json:"reply_count"`
+	LastReplyAt  *time.Time `json:"last_reply_at"`
+	Participants []uint     `json:"participants"` // member id
+	UnreadCount  *int64     `json:"unread_count,omitempty"`
+}
+
+// detail pesan sistem supaya client bisa memperbarui daftar member tanpa parsing teks
+type SystemInfo struct {
+	Action    string `json:"action"`
+	ActorId   uint   `json:"actor_id"`
+	TargetIds []uint `json:"target_ids,omitempty"`
+	Role      string `json:"role,omitempty"`
+	ChatId    uint   `json:"chat_id,omitempty"`
+}
+
+// event ws selain payload chat
+type WsEvent struct {
+	Event string      `json:"event"`
+	Data  interface{} `json:"data"`
+}
+
+type ErrorEvent struct {
+	Action       string `json:"action"`
+	Message      string `json:"message"`
+	RetryAfterMs int64  `json:"retry_after_ms,omitempty"`
 }
 
 type StatusChatRead struct {
@@ -48,16 +118,68 @@ type StatusChatRead struct {
 
 //group
 type CreateGroupReq struct {
-	Name   string `json:"name"`
-	Desc   string `json:"desc"`
-	UserId uint   `json:"-"`
+	Name         string `json:"name"`
+	Desc         string `json:"desc"`
+	Announcement bool   `json:"announcement"`
+	UserId       uint   `json:"-"`
 }
 
 type UpdateGroupReq struct {
-	Name     string `json:"name"`
-	Desc     string `json:"desc"`
-	MemberId uint   `json:"-"`
-	GroupId  uint   `json:"-"`
+	Name            string    `json:"name"`
+	Desc            string    `json:"desc"`
+	Announcement    *bool     `json:"announcement"`
+	SlowMode        *int      `json:"slow_mode_seconds"`
+	ForwardAuthor   *bool     `json:"forward_author"`
+	AttachmentMaxMB *int      `json:"attachment_max_mb"`
+	AttachmentTypes *[]string `json:"attachment_types"`
+	MemberId        uint      `json:"-"`
+	GroupId         uint      `json:"-"`
+}
+
+type UserBrief struct {
+	UserId   uint   `json:"user_id"`
+	Username string `json:"username"`
+}
+
+type GroupResponse struct {
+	GroupId         uint              `json:"group_id"`
+	Type            string            `json:"type"`
+	Name            string            `json:"name"`
+	Desc            string            `json:"desc"`
+	Announcement    bool              `json:"announcement"`
+	SlowMode        int               `json:"slow_mode_seconds"`
+	ForwardAuthor   bool              `json:"forward_author"`
+	AttachmentMaxMB int               `json:"attachment_max_mb"` // 0 berarti memakai batas server
+	AttachmentTypes []string          `json:"attachment_types,omitempty"`
+	Avatar          map[string]string `json:"avatar,omitempty"` // url per ukuran
+	Banner          map[string]string `json:"banner,omitempty"`
+	AvatarKey       string            `json:"-"`
+	BannerKey       string            `json:"-"`
+	Peer            *UserBrief        `json:"peer,omitempty"`
+}
+
+//inbox
+type InboxItem struct {
+	GroupResponse
+	LastMessage   string     `json:"last_message"`
+	LastMessageAt *time.Time `json:"last_message_at"`
+	UnreadCount   int64      `json:"unread_count"`
+	UnreadMention int64      `json:"unread_mentions"`
+}
+
+type GroupClosedEvent struct {
+	GroupId uint   `json:"group_id"`
+	Reason  string `json:"reason"`
+}
+
+//search
+type SearchResult struct {
+	GroupId   uint      `json:"group_id"`
+	GroupName string    `json:"group_name"`
+	ChatId    uint      `json:"chat_id"`
+	MemberId  *uint     `json:"member_id"`
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 //group members
@@ -69,6 +191,7 @@ type AddMemberReq struct {
 
 type RemoveMemberReq struct {
 	AdminId uint   `json:"-"`
+	GroupId uint   `json:"-"`
 	UserIds []uint `json:"user_id"`
 }
 
@@ -79,6 +202,335 @@ type UpdateRoleMember struct {
 	Role     string `json:"role"`
 }
 
+type NicknameReq struct {
+	ActorId  uint   `json:"-"`
+	GroupId  uint   `json:"-"`
+	MemberId uint   `json:"member_id"` // kosong berarti nickname sendiri
+	Nickname string `json:"nickname"`
+}
+
+type MemberResponse struct {
+	MemberId    uint      `json:"member_id"`
+	UserId      uint      `json:"user_id"`
+	Username    string    `json:"username"`
+	Nickname    string    `json:"nickname"`
+	DisplayName string    `json:"display_name"`
+	Role        string    `json:"role"`
+	JoinedAt    time.Time `json:"joined_at"`
+}
+
+type TransferOwnershipReq struct {
+	OwnerId  uint `json:"-"`
+	GroupId  uint `json:"-"`
+	MemberId uint `json:"member_id"`
+}
+
+//permission
+type UpdatePermissionReq struct {
+	AdminId     uint            `json:"-"`
+	GroupId     uint            `json:"-"`
+	Role        string          `json:"role"`
+	Permissions map[string]bool `json:"permissions"`
+}
+
+//moderation
+type SanctionReq struct {
+	AdminId uint   `json:"-"`
+	GroupId uint   `json:"-"`
+	Type    string `json:"-"`
+	UserId  uint   `json:"user_id"`
+	Minutes int    `json:"duration_minutes"` // 0 berarti permanen
+	Reason  string `json:"reason"`
+}
+
+type LiftSanctionReq struct {
+	AdminId uint
+	GroupId uint
+	Type    string
+	UserId  uint
+}
+
+type SanctionResponse struct {
+	GroupId   uint       `json:"group_id"`
+	UserId    uint       `json:"user_id"`
+	Type      string     `json:"type"`
+	Reason    string     `json:"reason"`
+	IssuedBy  uint       `json:"issued_by"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	CreatedAt time.Time  `json:"created_at"`
+	Active    bool       `json:"active"`
+}
+
+//retention
+type RetentionReq struct {
+	AdminId       uint  `json:"-"`
+	GroupId       uint  `json:"-"`
+	RetentionDays *int  `json:"retention_days"` // 0 berarti selamanya
+	UseDefault    bool  `json:"use_default"`
+	LegalHold     *bool `json:"legal_hold"`
+}
+
+type RetentionResponse struct {
+	GroupId       uint `json:"group_id"`
+	RetentionDays *int `json:"retention_days"`
+	EffectiveDays int  `json:"effective_days"`
+	LegalHold     bool `json:"legal_hold"`
+}
+
+//audit
+type AuditFilter struct {
+	GroupId  uint
+	Action   string
+	ActorId  uint
+	TargetId uint
+	Since    *time.Time
+	Until    *time.Time
+	Page     int
+	Limit    int
+}
+
+type AuditResponse struct {
+	ID        uint            `json:"id"`
+	GroupId   uint            `json:"group_id"`
+	ActorId   uint            `json:"actor_id"`
+	Action    string          `json:"action"`
+	TargetId  *uint           `json:"target_id"`
+	Before    json.RawMessage `json:"before"`
+	After     json.RawMessage `json:"after"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type AuditPage struct {
+	Items []AuditResponse `json:"items"`
+	Page  int             `json:"page"`
+	Limit int             `json:"limit"`
+	Total int64           `json:"total"`
+}
+
+//topic
+type TopicReq struct {
+	ActorId uint   `json:"-"`
+	GroupId uint   `json:"-"`
+	TopicId uint   `json:"-"`
+	Name    string `json:"name"`
+}
+
+type TopicResponse struct {
+	TopicId     uint       `json:"topic_id"` // 0 berarti topic umum
+	GroupId     uint       `json:"group_id"`
+	Name        string     `json:"name"`
+	CreatedBy   uint       `json:"created_by,omitempty"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+	UnreadCount int64      `json:"unread_count"`
+}
+
+//image
+type GroupImageReq struct {
+	AdminId uint
+	GroupId uint
+	Kind    string
+	Data    []byte
+}
+
+//import
+type ImportMembersReq struct {
+	AdminId uint
+	GroupId uint
+	DryRun  bool
+	File    io.Reader
+}
+
+type ImportRow struct {
+	Line       int    `json:"line"`
+	Identifier string `json:"identifier"`
+	Role       string `json:"role"`
+	UserId     uint   `json:"user_id,omitempty"`
+	Status     string `json:"status"`
+	Message    string `json:"message,omitempty"`
+}
+
+type ImportReport struct {
+	DryRun bool        `json:"dry_run"`
+	Total  int         `json:"total"`
+	Valid  int         `json:"valid"`
+	Added  int         `json:"added"`
+	Rows   []ImportRow `json:"rows"`
+}
+
+//limit
+type Capabilities struct {
+	MaxMembersPerGroup int      `json:"max_members_per_group"` // 0 berarti tanpa batas
+	MaxGroupsCreated   int      `json:"max_groups_created"`
+	MaxGroupsJoined    int      `json:"max_groups_joined"`
+	MaxMessageLength   int      `json:"max_message_length"`
+	MessageRateLimit   int      `json:"message_rate_limit"`
+	MessageRateWindow  int      `json:"message_rate_window_seconds"`
+	EditWindow         int      `json:"edit_window_minutes"`
+	MaxPins            int      `json:"max_pins_per_group"`
+	MaxAttachmentMB    int      `json:"max_attachment_mb"`
+	AttachmentTypes    []string `json:"attachment_types"`
+	GroupsCreated      int64    `json:"groups_created"`
+	GroupsJoined       int64    `json:"groups_joined"`
+}
+
+//history
+type ChatCursor struct {
+	CreatedAt time.Time
+	ID        uint
+}
+
+type ChatFilter struct {
+	ViewerId uint // member yang melihat, untuk reacted_by_me
+	GroupId  uint
+	TopicId  *uint
+	ThreadId *uint // nil berarti timeline utama
+}
+
+type MessagePageReq struct {
+	MemberId uint
+	GroupId  uint
+	TopicId  *uint
+	ThreadId *uint
+	Before   string
+	After    string
+	Limit    int
+}
+
+// Before dipakai untuk mengambil halaman yang lebih lama, After untuk yang lebih baru.
+// Keduanya kosong jika tidak ada lagi pesan ke arah tersebut.
+type MessagePage struct {
+	Items  []ResponseChat `json:"items"`
+	Before string         `json:"before,omitempty"`
+	After  string         `json:"after,omitempty"`
+}
+
+//thread
+type ThreadPage struct {
+	Root *ResponseChat `json:"root"`
+	MessagePage
+}
+
+type ThreadEvent struct {
+	RootId uint        `json:"root_id"`
+	Thread *ThreadInfo `json:"thread"`
+}
+
+//reaction
+type ReactionReq struct {
+	MemberId uint
+	GroupId  uint
+	ChatId   uint
+	Emoji    string
+	Add      bool
+}
+
+type ReactionCount struct {
+	Emoji       string `json:"emoji"`
+	Count       int64  `json:"count"`
+	ReactedByMe bool   `json:"reacted_by_me"`
+}
+
+type ReactionEvent struct {
+	ChatId   uint   `json:"chat_id"`
+	MemberId uint   `json:"member_id"`
+	Emoji    string `json:"emoji"`
+	Added    bool   `json:"added"`
+	Count    int64  `json:"count"`
+}
+
+//mention
+// posisi mention di isi pesan untuk di-highlight client, dihitung dalam karakter (rune)
+type MentionSpan struct {
+	Offset   int    `json:"offset"`
+	Length   int    `json:"length"`
+	Kind     string `json:"kind"`
+	MemberId uint   `json:"member_id,omitempty"`
+}
+
+type MentionEvent struct {
+	GroupId uint            `json:"group_id"`
+	TopicId *uint           `json:"topic_id,omitempty"`
+	Chat    json.RawMessage `json:"chat"`
+	UserIds []uint          `json:"-"`
+}
+
+//attachment
+type AttachmentUploadReq struct {
+	MemberId uint
+	GroupId  uint
+	Name     string
+	File     io.Reader
+}
+
+type AttachmentResponse struct {
+	ID          uint   `json:"attachment_id"`
+	Name        string `json:"name"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+}
+
+type AttachmentURL struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+//forward
+type ForwardReq struct {
+	UserId        uint
+	GroupId       uint
+	ChatId        uint
+	TargetGroupId uint
+	TopicId       *uint // topic di grup tujuan
+}
+
+// ForwardInfo kosong berarti grup asal tidak mengizinkan penulis asli ditampilkan
+type ForwardInfo struct {
+	ChatId   uint   `json:"chat_id,omitempty"`
+	GroupId  uint   `json:"group_id,omitempty"`
+	UserId   uint   `json:"user_id,omitempty"`
+	Username string `json:"username,omitempty"`
+}
+
+//pin
+type PinReq struct {
+	MemberId uint
+	GroupId  uint
+	ChatId   uint
+	Pin      bool
+}
+
+type PinEvent struct {
+	ChatId   uint       `json:"chat_id"`
+	Pinned   bool       `json:"pinned"`
+	MemberId uint       `json:"member_id"`
+	PinnedAt *time.Time `json:"pinned_at,omitempty"`
+}
+
+//delete
+type DeleteChatReq struct {
+	MemberId uint
+	GroupId  uint
+	ChatId   uint
+	ForMe    bool // hanya disembunyikan untuk member ini
+}
+
+type HiddenEvent struct {
+	GroupId uint `json:"group_id"`
+	ChatId  uint `json:"chat_id"`
+}
+
+//revision
+type ChatRevision struct {
+	Message    string     `json:"message"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ReplacedAt *time.Time `json:"replaced_at"` // nil untuk isi yang berlaku sekarang
+}
+
+type ChatEditHistory struct {
+	ChatId    uint           `json:"chat_id"`
+	Revisions []ChatRevision `json:"revisions"` // urut dari isi pertama
+}
+
 //chat read
 type MemberStatus struct {
 	MemberId uint `json:"member_id"`
@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// GetEnvInt mengembalikan fallback jika env kosong atau bukan angka
+func GetEnvInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// GetEnvList memecah env yang dipisah koma, item kosong diabaikan
+func GetEnvList(key string, fallback []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	return SplitList(value)
+}
+
+func SplitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -1,6 +1,10 @@
 package utils
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
 
 var (
 	ErrInternal = errors.New("internal error")
@@ -12,4 +16,103 @@ var (
 	ErrNotAdmin  = errors.New("kau bukan admin")
 	ErrNotMember = errors.New("kau bukan member")
 	ErrrnotChat  = errors.New("chat ini bukan milikmu")
+
+	//revision
+	ErrEditWindowExpired = errors.New("batas waktu untuk mengedit pesan ini sudah lewat")
+
+	//reply
+	ErrChatNotFound = errors.New("pesan tidak ditemukan")
+	ErrInvalidReply = errors.New("pesan yang dibalas tidak ada di grup atau topic ini")
+
+	//thread
+	ErrInvalidThread = errors.New("thread tidak ditemukan di grup atau topic ini")
+
+	//attachment
+	ErrEmptyAttachment        = errors.New("file lampiran kosong")
+	ErrAttachmentTooLarge     = errors.New("ukuran lampiran melebihi batas")
+	ErrAttachmentType         = errors.New("jenis file lampiran tidak diizinkan")
+	ErrAttachmentNotFound     = errors.New("lampiran tidak ditemukan")
+	ErrInvalidAttachment      = errors.New("lampiran tidak valid atau sudah dipakai pesan lain")
+	ErrTooManyAttachments     = errors.New("satu pesan maksimal 10 lampiran")
+	ErrInvalidAttachmentLimit = errors.New("batas lampiran tidak valid")
+
+	//forward
+	ErrForwardTarget = errors.New("kau bukan member grup tujuan")
+
+	//pin
+	ErrPinLimit      = errors.New("jumlah pesan yang disematkan di grup ini sudah maksimal")
+	ErrAlreadyPinned = errors.New("pesan ini sudah disematkan")
+	ErrNotPinned     = errors.New("pesan ini tidak sedang disematkan")
+
+	//mention
+	ErrMentionEveryone = errors.New("kau tidak punya izin untuk mention @everyone")
+
+	//reaction
+	ErrInvalidEmoji     = errors.New("emoji tidak valid")
+	ErrTooManyReactions = errors.New("jumlah jenis reaksi di pesan ini sudah maksimal")
+
+	//history
+	ErrInvalidCursor = errors.New("cursor tidak valid")
+
+	//role
+	ErrNotOwner        = errors.New("kau bukan owner")
+	ErrInvalidRole     = errors.New("role tidak valid")
+	ErrRoleTooHigh     = errors.New("kau hanya bisa mengatur member dengan role di bawahmu")
+	ErrTargetNotMember = errors.New("user tersebut bukan member grup ini")
+	ErrInvalidNickname = errors.New("nickname maksimal 64 karakter")
+
+	//permission
+	ErrForbidden         = errors.New("kau tidak punya izin untuk aksi ini")
+	ErrInvalidPermission = errors.New("permission tidak valid")
+	ErrAnnouncementOnly  = errors.New("hanya admin yang bisa mengirim pesan di grup pengumuman")
+
+	//moderation
+	ErrMuted           = errors.New("kau sedang di-mute di grup ini")
+	ErrUserBanned      = errors.New("user ini di-ban dari grup ini")
+	ErrInvalidDuration = errors.New("durasi tidak valid")
+	ErrNoSanction      = errors.New("user ini tidak sedang di-mute atau di-ban")
+
+	//group
+	ErrGroupNotFound   = errors.New("grup tidak ditemukan")
+	ErrGroupArchived   = errors.New("grup ini sudah diarsipkan")
+	ErrGroupNotDeleted = errors.New("grup ini tidak sedang dihapus")
+	ErrGraceExpired    = errors.New("masa tenggang restore grup sudah lewat")
+	ErrInvalidSlowMode = errors.New("slow mode harus antara 0 dan 21600 detik")
+
+	//topic
+	ErrTopicNotFound    = errors.New("topic tidak ditemukan")
+	ErrTopicExists      = errors.New("nama topic sudah dipakai")
+	ErrInvalidTopicName = errors.New("nama topic harus 1 sampai 64 karakter")
+
+	//limit
+	ErrGroupFull      = errors.New("jumlah member grup sudah mencapai batas")
+	ErrCreateQuota    = errors.New("kau sudah mencapai batas jumlah grup yang bisa dibuat")
+	ErrJoinQuota      = errors.New("user sudah mencapai batas jumlah grup yang bisa diikuti")
+	ErrMessageTooLong = errors.New("pesan terlalu panjang")
+
+	//image
+	ErrInvalidImage  = errors.New("file harus berupa gambar jpeg, png atau gif")
+	ErrImageTooLarge = errors.New("gambar maksimal 5MB, sisi terpanjang 6000 piksel dan total 16 megapiksel")
+
+	//import
+	ErrInvalidCSV     = errors.New("file csv tidak valid")
+	ErrEmptyImport    = errors.New("file csv tidak berisi data")
+	ErrImportTooLarge = errors.New("import maksimal 1000 baris")
+
+	//retention
+	ErrInvalidRetention = errors.New("masa retensi tidak valid")
+
+	//direct message
+	ErrUserNotFound = errors.New("user tidak ditemukan")
+	ErrSelfChat     = errors.New("tidak bisa chat dengan diri sendiri")
+	ErrDirectChat   = errors.New("aksi ini tidak bisa untuk chat pribadi")
 )
+
+// RateLimitError dikembalikan saat member mengirim pesan terlalu cepat
+type RateLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("terlalu cepat, coba lagi dalam %.0f detik", e.RetryAfter.Round(time.Second).Seconds())
+}
@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// MentionToken adalah satu @nama di isi pesan, Offset dan Length dihitung dalam rune
+type MentionToken struct {
+	Name   string
+	Offset int
+	Length int
+}
+
+func isMentionRune(r rune) bool {
+	return !unicode.IsSpace(r) && !strings.ContainsRune(",;:!?()[]{}<>\"'`@", r)
+}
+
+// ParseMentions mencari @nama yang diawali awal teks atau karakter selain huruf/angka,
+// sehingga alamat email tidak ikut terbaca sebagai mention
+func ParseMentions(text string) []MentionToken {
+	runes := []rune(text)
+	tokens := make([]MentionToken, 0)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != '@' {
+			continue
+		}
+		if i > 0 && (unicode.IsLetter(runes[i-1]) || unicode.IsDigit(runes[i-1]) || runes[i-1] == '_') {
+			continue
+		}
+
+		end := i + 1
+		for end < len(runes) && isMentionRune(runes[end]) {
+			end++
+		}
+		// titik di akhir kalimat bukan bagian dari username
+		for end > i+1 && runes[end-1] == '.' {
+			end--
+		}
+		if end == i+1 {
+			continue
+		}
+
+		tokens = append(tokens, MentionToken{
+			Name:   string(runes[i+1 : end]),
+			Offset: i,
+			Length: end - i,
+		})
+		i = end - 1
+	}
+
+	return tokens
+}
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"api_chat_ws/dto"
+	"api_chat_ws/helper/middleware"
+	"api_chat_ws/helper/utils"
+	"api_chat_ws/ws"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+func (h *WebSocketHandler) ArchiveGroup(w http.ResponseWriter, r *http.Request) {
+	h.setArchived(w, r, true)
+}
+
+func (h *WebSocketHandler) UnarchiveGroup(w http.ResponseWriter, r *http.Request) {
+	h.setArchived(w, r, false)
+}
+
+func (h *WebSocketHandler) setArchived(w http.ResponseWriter, r *http.Request, archive bool) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, valid := claimsRaw.(*utils.JWTCLAIMS)
+	if !valid {
+		utils.WriteError(w, http.StatusUnauthorized, "invalid jwt")
+		return
+	}
+
+	params := mux.Vars(r)
+	paramsGroupid, _ := strconv.Atoi(params["groupId"])
+
+	memberId, err := h.usecase.GetMemberId(claims.UserID, uint(paramsGroupid))
+	if err != nil {
+		switch err {
+		case utils.ErrNotMember:
+			utils.WriteError(w, http.StatusForbidden, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	if err := h.usecase.ArchiveGroup(memberId, uint(paramsGroupid), archive); err != nil {
+		switch err {
+		case utils.ErrNotAdmin:
+			utils.WriteError(w, http.StatusForbidden, err.Error())
+			return
+		case utils.ErrDirectChat:
+			utils.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		case utils.ErrGroupNotFound:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	if archive {
+		h.hub.CloseGroup(uint(paramsGroupid), ws.NewEvent(ws.EventGroupClosed, dto.GroupClosedEvent{
+			GroupId: uint(paramsGroupid),
+			Reason:  "archived",
+		}))
+	}
+
+	utils.WriteJSON(w, http.StatusOK, nil)
+}
+
+func (h *WebSocketHandler) RestoreGroup(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, valid := claimsRaw.(*utils.JWTCLAIMS)
+	if !valid {
+		utils.WriteError(w, http.StatusUnauthorized, "invalid jwt")
+		return
+	}
+
+	params := mux.Vars(r)
+	paramsGroupid, _ := strconv.Atoi(params["groupId"])
+
+	if err := h.usecase.RestoreGroup(claims.UserID, uint(paramsGroupid)); err != nil {
+		switch err {
+		case utils.ErrNotMember, utils.ErrNotOwner:
+			utils.WriteError(w, http.StatusForbidden, err.Error())
+			return
+		case utils.ErrGroupNotDeleted:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		case utils.ErrGraceExpired:
+			utils.WriteError(w, http.StatusGone, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, nil)
+}
+
+func (h *WebSocketHandler) SearchChats(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, valid := claimsRaw.(*utils.JWTCLAIMS)
+	if !valid {
+		utils.WriteError(w, http.StatusUnauthorized, "invalid jwt")
+		return
+	}
+
+	query := r.URL.Query()
+	var groupId int
+	if v := query.Get("group_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			utils.WriteError(w, http.StatusBadRequest, "invalid group_id")
+			return
+		}
+		groupId = id
+	}
+
+	results, err := h.usecase.SearchChats(claims.UserID, query.Get("q"), uint(groupId))
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, results)
+}
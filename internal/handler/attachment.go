@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"api_chat_ws/dto"
+	"api_chat_ws/helper/middleware"
+	"api_chat_ws/helper/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// UploadAttachment menerima lampiran lewat form field "file". Body dibaca bertahap,
+// ukuran maksimal dicek di usecase sesuai batas grup.
+func (h *WebSocketHandler) UploadAttachment(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, valid := claimsRaw.(*utils.JWTCLAIMS)
+	if !valid {
+		utils.WriteError(w, http.StatusUnauthorized, "invalid jwt")
+		return
+	}
+
+	params := mux.Vars(r)
+	paramsGroupid, _ := strconv.Atoi(params["groupId"])
+
+	memberId, err := h.usecase.GetMemberId(claims.UserID, uint(paramsGroupid))
+	if err != nil {
+		switch err {
+		case utils.ErrNotMember:
+			utils.WriteError(w, http.StatusForbidden, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid file")
+		return
+	}
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			utils.WriteError(w, http.StatusBadRequest, "invalid file")
+			return
+		}
+		if part.FormName() != "file" || part.FileName() == "" {
+			part.Close()
+			continue
+		}
+		defer part.Close()
+
+		attachment, err := h.usecase.UploadAttachment(&dto.AttachmentUploadReq{
+			MemberId: memberId,
+			GroupId:  uint(paramsGroupid),
+			Name:     part.FileName(),
+			File:     part,
+		})
+		if err != nil {
+			switch err {
+			case utils.ErrForbidden, utils.ErrGroupArchived, utils.ErrAnnouncementOnly, utils.ErrMuted:
+				utils.WriteError(w, http.StatusForbidden, err.Error())
+				return
+			case utils.ErrEmptyAttachment:
+				utils.WriteError(w, http.StatusBadRequest, err.Error())
+				return
+			case utils.ErrAttachmentType:
+				utils.WriteError(w, http.StatusUnsupportedMediaType, err.Error())
+				return
+			case utils.ErrAttachmentTooLarge:
+				utils.WriteError(w, http.StatusRequestEntityTooLarge, err.Error())
+				return
+			default:
+				utils.WriteError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+		}
+
+		utils.WriteJSON(w, http.StatusCreated, attachment)
+		return
+	}
+}
+
+// GetAttachmentURL mengembalikan URL unduhan bertanda tangan yang berlaku sebentar
+func (h *WebSocketHandler) GetAttachmentURL(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, valid := claimsRaw.(*utils.JWTCLAIMS)
+	if !valid {
+		utils.WriteError(w, http.StatusUnauthorized, "invalid jwt")
+		return
+	}
+
+	params := mux.Vars(r)
+	paramsGroupid, _ := strconv.Atoi(params["groupId"])
+	paramsAttachmentid, _ := strconv.Atoi(params["attachmentId"])
+
+	memberId, err := h.usecase.GetMemberId(claims.UserID, uint(paramsGroupid))
+	if err != nil {
+		switch err {
+		case utils.ErrNotMember:
+			utils.WriteError(w, http.StatusForbidden, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	url, err := h.usecase.GetAttachmentURL(memberId, uint(paramsGroupid), uint(paramsAttachmentid))
+	if err != nil {
+		switch err {
+		case utils.ErrAttachmentNotFound:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, url)
+}
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"api_chat_ws/dto"
+	"api_chat_ws/helper/middleware"
+	"api_chat_ws/helper/utils"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func (h *WebSocketHandler) GetAudit(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, valid := claimsRaw.(*utils.JWTCLAIMS)
+	if !valid {
+		utils.WriteError(w, http.StatusUnauthorized, "invalid jwt")
+		return
+	}
+
+	params := mux.Vars(r)
+	paramsGroupid, _ := strconv.Atoi(params["groupId"])
+
+	query := r.URL.Query()
+	filter := dto.AuditFilter{
+		GroupId: uint(paramsGroupid),
+		Action:  query.Get("action"),
+	}
+	filter.Page, _ = strconv.Atoi(query.Get("page"))
+	filter.Limit, _ = strconv.Atoi(query.Get("limit"))
+	if v := query.Get("actor_id"); v != "" {
+		actorId, err := strconv.Atoi(v)
+		if err != nil {
+			utils.WriteError(w, http.StatusBadRequest, "invalid actor_id")
+			return
+		}
+		filter.ActorId = uint(actorId)
+	}
+	if v := query.Get("target_id"); v != "" {
+		targetId, err := strconv.Atoi(v)
+		if err != nil {
+			utils.WriteError(w, http.StatusBadRequest, "invalid target_id")
+			return
+		}
+		filter.TargetId = uint(targetId)
+	}
+	if v := query.Get("since"); v != "" {
+		since, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			utils.WriteError(w, http.StatusBadRequest, "invalid since")
+			return
+		}
+		filter.Since = &since
+	}
+	if v := query.Get("until"); v != "" {
+		until, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			utils.WriteError(w, http.StatusBadRequest, "invalid until")
+			return
+		}
+		filter.Until = &until
+	}
+
+	memberId, err := h.usecase.GetMemberId(claims.UserID, uint(paramsGroupid))
+	if err != nil {
+		switch err {
+		case utils.ErrNotMember:
+			utils.WriteError(w, http.StatusForbidden, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	audits, err := h.usecase.GetAudit(memberId, &filter)
+	if err != nil {
+		switch err {
+		case utils.ErrNotAdmin:
+			utils.WriteError(w, http.StatusForbidden, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, audits)
+}
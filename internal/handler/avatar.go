@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"api_chat_ws/dto"
+	"api_chat_ws/helper/middleware"
+	"api_chat_ws/helper/utils"
+	"api_chat_ws/internal/usecase"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// batas body sedikit di atas batas gambar untuk overhead multipart, ukuran gambar dicek lagi di usecase
+const maxImageUpload = 6 << 20
+
+func (h *WebSocketHandler) UploadAvatar(w http.ResponseWriter, r *http.Request) {
+	h.uploadGroupImage(w, r, usecase.GroupImageAvatar)
+}
+
+func (h *WebSocketHandler) UploadBanner(w http.ResponseWriter, r *http.Request) {
+	h.uploadGroupImage(w, r, usecase.GroupImageBanner)
+}
+
+func (h *WebSocketHandler) DeleteAvatar(w http.ResponseWriter, r *http.Request) {
+	h.deleteGroupImage(w, r, usecase.GroupImageAvatar)
+}
+
+func (h *WebSocketHandler) DeleteBanner(w http.ResponseWriter, r *http.Request) {
+	h.deleteGroupImage(w, r, usecase.GroupImageBanner)
+}
+
+// uploadGroupImage menerima gambar lewat form field "file"
+func (h *WebSocketHandler) uploadGroupImage(w http.ResponseWriter, r *http.Request, kind string) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, valid := claimsRaw.(*utils.JWTCLAIMS)
+	if !valid {
+		utils.WriteError(w, http.StatusUnauthorized, "invalid jwt")
+		return
+	}
+
+	params := mux.Vars(r)
+	paramsGroupid, _ := strconv.Atoi(params["groupId"])
+
+	memberId, err := h.usecase.GetMemberId(claims.UserID, uint(paramsGroupid))
+	if err != nil {
+		switch err {
+		case utils.ErrNotMember:
+			utils.WriteError(w, http.StatusForbidden, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImageUpload)
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid file")
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid file")
+		return
+	}
+
+	urls, err := h.usecase.UploadGroupImage(&dto.GroupImageReq{
+		AdminId: memberId,
+		GroupId: uint(paramsGroupid),
+		Kind:    kind,
+		Data:    data,
+	})
+	if err != nil {
+		switch err {
+		case utils.ErrForbidden, utils.ErrGroupArchived:
+			utils.WriteError(w, http.StatusForbidden, err.Error())
+			return
+		case utils.ErrInvalidImage, utils.ErrDirectChat:
+			utils.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		case utils.ErrImageTooLarge:
+			utils.WriteError(w, http.StatusRequestEntityTooLarge, err.Error())
+			return
+		case utils.ErrGroupNotFound:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, urls)
+}
+
+func (h *WebSocketHandler) deleteGroupImage(w http.ResponseWriter, r *http.Request, kind string) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, valid := claimsRaw.(*utils.JWTCLAIMS)
+	if !valid {
+		utils.WriteError(w, http.StatusUnauthorized, "invalid jwt")
+		return
+	}
+
+	params := mux.Vars(r)
+	paramsGroupid, _ := strconv.Atoi(params["groupId"])
+
+	memberId, err := h.usecase.GetMemberId(claims.UserID, uint(paramsGroupid))
+	if err != nil {
+		switch err {
+		case utils.ErrNotMember:
+			utils.WriteError(w, http.StatusForbidden, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	err = h.usecase.DeleteGroupImage(&dto.GroupImageReq{
+		AdminId: memberId,
+		GroupId: uint(paramsGroupid),
+		Kind:    kind,
+	})
+	if err != nil {
+		switch err {
+		case utils.ErrForbidden, utils.ErrGroupArchived:
+			utils.WriteError(w, http.StatusForbidden, err.Error())
+			return
+		case utils.ErrGroupNotFound:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, nil)
+}
@@ -62,9 +62,29 @@ func (h *WebSocketHandler) ServeWS(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// ?topic= membatasi koneksi ke satu topic, tanpa parameter menerima semua topic
+	var topicId *uint
+	if rawTopic := r.URL.Query().Get("topic"); rawTopic != "" {
+		parsed, err := strconv.ParseUint(rawTopic, 10, 64)
+		if err != nil {
+			fmt.Printf("err %v", err)
+			conn.Close()
+			return
+		}
+		id := uint(parsed)
+		if err := h.usecase.ValidateTopic(uint(groupID), &id); err != nil {
+			fmt.Printf("err %v", err)
+			conn.Close()
+			return
+		}
+		topicId = &id
+	}
+
 	client := &ws.Client{
 		MemberId: memberId,
+		UserID:   claims.UserID,
 		GroupID:  uint(groupID),
+		TopicID:  topicId,
 		Conn:     conn,
 		Send:     make(chan []byte, 256), // buffer biar nggak nge-block
 	}
@@ -78,6 +98,18 @@ func (h *WebSocketHandler) ServeWS(w http.ResponseWriter, r *http.Request) {
 	go client.ReadPump(h.hub, h.usecase)
 }
 
+// broadcast meneruskan pesan (misalnya pesan sistem) ke semua koneksi di grup
+func (h *WebSocketHandler) broadcast(groupId uint, message []byte) {
+	if message == nil {
+		return
+	}
+
+	h.hub.Broadcast <- ws.BroadcastMessage{
+		GroupID: groupId,
+		Message: message,
+	}
+}
+
 func (h *WebSocketHandler) CreateGroup(w http.ResponseWriter, r *http.Request) {
 	claimsRaw := r.Context().Value(middleware.UserContextKey)
 	claims, valid := claimsRaw.(*utils.JWTCLAIMS)
@@ -93,9 +125,14 @@ func (h *WebSocketHandler) CreateGroup(w http.ResponseWriter, r *http.Request) {
 
 	req.UserId = claims.UserID
 	if err := h.usecase.CreateGroup(&req); err != nil {
-		utils.WriteError(w, http.StatusInternalServerError, err.Error())
-
-		return
+		switch err {
+		case utils.ErrCreateQuota, utils.ErrJoinQuota:
+			utils.WriteError(w, http.StatusForbidden, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
 	}
 
 	utils.WriteJSON(w, http.StatusOK, nil)
@@ -122,12 +159,10 @@ func (h *WebSocketHandler) UpdateGroup(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		switch err {
 		case utils.ErrNotMember:
-			fmt.Printf("err %v", err)
+			utils.WriteError(w, http.StatusForbidden, err.Error())
 			return
-
 		default:
-			fmt.Printf("err %v", err)
-
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
 			return
 		}
 	}
@@ -136,9 +171,15 @@ func (h *WebSocketHandler) UpdateGroup(w http.ResponseWriter, r *http.Request) {
 	req.MemberId = memberId
 	if err := h.usecase.UpdateGroup(&req); err != nil {
 		switch err {
-		case utils.ErrNotAdmin:
+		case utils.ErrForbidden, utils.ErrNotAdmin, utils.ErrGroupArchived:
 			utils.WriteError(w, http.StatusForbidden, err.Error())
 			return
+		case utils.ErrDirectChat, utils.ErrInvalidSlowMode, utils.ErrInvalidAttachmentLimit:
+			utils.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		case utils.ErrGroupNotFound:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
 		default:
 			utils.WriteError(w, http.StatusInternalServerError, err.Error())
 			return
@@ -163,27 +204,36 @@ func (h *WebSocketHandler) DeleteGroup(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		switch err {
 		case utils.ErrNotMember:
-			fmt.Printf("err %v", err)
+			utils.WriteError(w, http.StatusForbidden, err.Error())
 			return
-
 		default:
-			fmt.Printf("err %v", err)
-
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
 			return
 		}
 	}
 
 	if err := h.usecase.DeleteGroup(memberId, uint(paramsGroupid)); err != nil {
 		switch err {
-		case utils.ErrNotAdmin:
+		case utils.ErrNotOwner:
 			utils.WriteError(w, http.StatusForbidden, err.Error())
 			return
+		case utils.ErrDirectChat:
+			utils.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		case utils.ErrGroupNotFound:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
 		default:
 			utils.WriteError(w, http.StatusInternalServerError, err.Error())
 			return
 		}
 	}
 
+	h.hub.CloseGroup(uint(paramsGroupid), ws.NewEvent(ws.EventGroupClosed, dto.GroupClosedEvent{
+		GroupId: uint(paramsGroupid),
+		Reason:  "deleted",
+	}))
+
 	utils.WriteJSON(w, http.StatusOK, nil)
 }
 
@@ -208,29 +258,39 @@ func (h *WebSocketHandler) AddMembers(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		switch err {
 		case utils.ErrNotMember:
-			fmt.Printf("err %v", err)
+			utils.WriteError(w, http.StatusForbidden, err.Error())
 			return
-
 		default:
-			fmt.Printf("err %v", err)
-
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
 			return
 		}
 	}
 
 	req.AdminId = memberId
 	req.GroupId = uint(paramsGroupid)
-	if err := h.usecase.AddMember(&req); err != nil {
+	system, err := h.usecase.AddMember(&req)
+	if err != nil {
 		switch err {
-		case utils.ErrNotAdmin:
+		case utils.ErrForbidden, utils.ErrGroupArchived:
 			utils.WriteError(w, http.StatusForbidden, err.Error())
 			return
+		case utils.ErrUserBanned, utils.ErrGroupFull, utils.ErrJoinQuota:
+			utils.WriteError(w, http.StatusConflict, err.Error())
+			return
+		case utils.ErrDirectChat:
+			utils.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		case utils.ErrGroupNotFound:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
 		default:
 			utils.WriteError(w, http.StatusInternalServerError, err.Error())
 			return
 		}
 	}
 
+	h.broadcast(req.GroupId, system)
+
 	utils.WriteJSON(w, http.StatusOK, nil)
 }
 
@@ -255,28 +315,47 @@ func (h *WebSocketHandler) RemoveMembers(w http.ResponseWriter, r *http.Request)
 	if err != nil {
 		switch err {
 		case utils.ErrNotMember:
-			fmt.Printf("err %v", err)
+			utils.WriteError(w, http.StatusForbidden, err.Error())
 			return
-
 		default:
-			fmt.Printf("err %v", err)
-
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
 			return
 		}
 	}
 
 	req.AdminId = memberId
-	if err := h.usecase.RemoveMember(req.UserIds, req.AdminId); err != nil {
+	req.GroupId = uint(paramsGroupid)
+	system, err := h.usecase.RemoveMember(&req)
+	if err != nil {
 		switch err {
-		case utils.ErrNotAdmin:
+		case utils.ErrNotAdmin, utils.ErrRoleTooHigh, utils.ErrGroupArchived:
 			utils.WriteError(w, http.StatusForbidden, err.Error())
 			return
+		case utils.ErrTargetNotMember:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		case utils.ErrDirectChat:
+			utils.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		case utils.ErrGroupNotFound:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
 		default:
 			utils.WriteError(w, http.StatusInternalServerError, err.Error())
 			return
 		}
 	}
 
+	// koneksi member yang dikeluarkan ditutup supaya tidak menerima broadcast grup lagi
+	closed := ws.NewEvent(ws.EventGroupClosed, dto.GroupClosedEvent{
+		GroupId: req.GroupId,
+		Reason:  "removed",
+	})
+	for _, userId := range req.UserIds {
+		h.hub.Kick(req.GroupId, userId, closed)
+	}
+	h.broadcast(req.GroupId, system)
+
 	utils.WriteJSON(w, http.StatusOK, nil)
 }
 
@@ -295,20 +374,37 @@ func (h *WebSocketHandler) ExitGroup(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		switch err {
 		case utils.ErrNotMember:
-			fmt.Printf("err %v", err)
+			utils.WriteError(w, http.StatusForbidden, err.Error())
 			return
-
 		default:
-			fmt.Printf("err %v", err)
-
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
 			return
 		}
 	}
-	if err := h.usecase.ExitGroup(memberId); err != nil {
-		utils.WriteError(w, http.StatusInternalServerError, err.Error())
-		return
+	system, err := h.usecase.ExitGroup(memberId, uint(paramsGroupid))
+	if err != nil {
+		switch err {
+		case utils.ErrDirectChat:
+			utils.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		case utils.ErrGroupNotFound:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		case utils.ErrGroupArchived:
+			utils.WriteError(w, http.StatusForbidden, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
 	}
 
+	h.hub.Kick(uint(paramsGroupid), claims.UserID, ws.NewEvent(ws.EventGroupClosed, dto.GroupClosedEvent{
+		GroupId: uint(paramsGroupid),
+		Reason:  "exited",
+	}))
+	h.broadcast(uint(paramsGroupid), system)
+
 	utils.WriteJSON(w, http.StatusOK, nil)
 }
 
@@ -333,28 +429,166 @@ func (h *WebSocketHandler) UpdateRoleMembers(w http.ResponseWriter, r *http.Requ
 	if err != nil {
 		switch err {
 		case utils.ErrNotMember:
-			fmt.Printf("err %v", err)
+			utils.WriteError(w, http.StatusForbidden, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
 			return
+		}
+	}
 
+	req.AdminId = memberId
+	req.GroupId = uint(paramsGroupid)
+	system, err := h.usecase.UpdateRoleUser(&req)
+	if err != nil {
+		switch err {
+		case utils.ErrNotAdmin, utils.ErrRoleTooHigh, utils.ErrGroupArchived:
+			utils.WriteError(w, http.StatusForbidden, err.Error())
+			return
+		case utils.ErrInvalidRole:
+			utils.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		case utils.ErrTargetNotMember:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		case utils.ErrDirectChat:
+			utils.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		case utils.ErrGroupNotFound:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
 		default:
-			fmt.Printf("err %v", err)
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	h.broadcast(req.GroupId, system)
+
+	utils.WriteJSON(w, http.StatusOK, nil)
+}
+
+func (h *WebSocketHandler) TransferOwnership(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, valid := claimsRaw.(*utils.JWTCLAIMS)
+	if !valid {
+		utils.WriteError(w, http.StatusUnauthorized, "invalid jwt")
+		return
+	}
+
+	params := mux.Vars(r)
+	paramsGroupid, _ := strconv.Atoi(params["groupId"])
 
+	var req dto.TransferOwnershipReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+
+	memberId, err := h.usecase.GetMemberId(claims.UserID, uint(paramsGroupid))
+	if err != nil {
+		switch err {
+		case utils.ErrNotMember:
+			utils.WriteError(w, http.StatusForbidden, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
 			return
 		}
 	}
 
-	req.AdminId = memberId
+	req.OwnerId = memberId
 	req.GroupId = uint(paramsGroupid)
-	if err := h.usecase.UpdateRoleUser(&req); err != nil {
+	system, err := h.usecase.TransferOwnership(&req)
+	if err != nil {
 		switch err {
-		case utils.ErrNotAdmin:
+		case utils.ErrNotOwner, utils.ErrGroupArchived:
 			utils.WriteError(w, http.StatusForbidden, err.Error())
 			return
+		case utils.ErrDirectChat:
+			utils.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		case utils.ErrTargetNotMember:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		case utils.ErrGroupNotFound:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
 		default:
 			utils.WriteError(w, http.StatusInternalServerError, err.Error())
 			return
 		}
 	}
 
+	h.broadcast(req.GroupId, system)
+
 	utils.WriteJSON(w, http.StatusOK, nil)
 }
+
+func (h *WebSocketHandler) OpenDirect(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, valid := claimsRaw.(*utils.JWTCLAIMS)
+	if !valid {
+		utils.WriteError(w, http.StatusUnauthorized, "invalid jwt")
+		return
+	}
+
+	params := mux.Vars(r)
+	targetId, err := strconv.Atoi(params["userId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	res, err := h.usecase.OpenDirect(claims.UserID, uint(targetId))
+	if err != nil {
+		switch err {
+		case utils.ErrUserNotFound:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		case utils.ErrSelfChat:
+			utils.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, res)
+}
+
+func (h *WebSocketHandler) Inbox(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, valid := claimsRaw.(*utils.JWTCLAIMS)
+	if !valid {
+		utils.WriteError(w, http.StatusUnauthorized, "invalid jwt")
+		return
+	}
+
+	archived := r.URL.Query().Get("archived") == "true"
+	inbox, err := h.usecase.GetInbox(claims.UserID, archived)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, inbox)
+}
+
+func (h *WebSocketHandler) Capabilities(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, valid := claimsRaw.(*utils.JWTCLAIMS)
+	if !valid {
+		utils.WriteError(w, http.StatusUnauthorized, "invalid jwt")
+		return
+	}
+
+	capabilities, err := h.usecase.GetCapabilities(claims.UserID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, capabilities)
+}
@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"api_chat_ws/dto"
+	"api_chat_ws/helper/middleware"
+	"api_chat_ws/helper/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+func (h *WebSocketHandler) GetMessages(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, valid := claimsRaw.(*utils.JWTCLAIMS)
+	if !valid {
+		utils.WriteError(w, http.StatusUnauthorized, "invalid jwt")
+		return
+	}
+
+	params := mux.Vars(r)
+	paramsGroupid, _ := strconv.Atoi(params["groupId"])
+
+	memberId, err := h.usecase.GetMemberId(claims.UserID, uint(paramsGroupid))
+	if err != nil {
+		switch err {
+		case utils.ErrNotMember:
+			utils.WriteError(w, http.StatusForbidden, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	query := r.URL.Query()
+	req := dto.MessagePageReq{
+		MemberId: memberId,
+		GroupId:  uint(paramsGroupid),
+		Before:   query.Get("before"),
+		After:    query.Get("after"),
+	}
+	req.Limit, _ = strconv.Atoi(query.Get("limit"))
+	if v := query.Get("topic"); v != "" {
+		topicId, err := strconv.Atoi(v)
+		if err != nil || topicId < 0 {
+			utils.WriteError(w, http.StatusBadRequest, "invalid topic")
+			return
+		}
+		id := uint(topicId)
+		req.TopicId = &id
+	}
+
+	page, err := h.usecase.GetMessages(&req)
+	if err != nil {
+		switch err {
+		case utils.ErrInvalidCursor:
+			utils.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		case utils.ErrTopicNotFound:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, page)
+}
+
+func (h *WebSocketHandler) GetThreads(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, valid := claimsRaw.(*utils.JWTCLAIMS)
+	if !valid {
+		utils.WriteError(w, http.StatusUnauthorized, "invalid jwt")
+		return
+	}
+
+	params := mux.Vars(r)
+	paramsGroupid, _ := strconv.Atoi(params["groupId"])
+
+	memberId, err := h.usecase.GetMemberId(claims.UserID, uint(paramsGroupid))
+	if err != nil {
+		switch err {
+		case utils.ErrNotMember:
+			utils.WriteError(w, http.StatusForbidden, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	threads, err := h.usecase.GetThreads(memberId, uint(paramsGroupid))
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, threads)
+}
+
+func (h *WebSocketHandler) GetThread(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, valid := claimsRaw.(*utils.JWTCLAIMS)
+	if !valid {
+		utils.WriteError(w, http.StatusUnauthorized, "invalid jwt")
+		return
+	}
+
+	params := mux.Vars(r)
+	paramsGroupid, _ := strconv.Atoi(params["groupId"])
+	paramsChatid, _ := strconv.Atoi(params["chatId"])
+
+	memberId, err := h.usecase.GetMemberId(claims.UserID, uint(paramsGroupid))
+	if err != nil {
+		switch err {
+		case utils.ErrNotMember:
+			utils.WriteError(w, http.StatusForbidden, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	query := r.URL.Query()
+	rootId := uint(paramsChatid)
+	req := dto.MessagePageReq{
+		MemberId: memberId,
+		GroupId:  uint(paramsGroupid),
+		ThreadId: &rootId,
+		Before:   query.Get("before"),
+		After:    query.Get("after"),
+	}
+	req.Limit, _ = strconv.Atoi(query.Get("limit"))
+
+	thread, err := h.usecase.GetThread(&req)
+	if err != nil {
+		switch err {
+		case utils.ErrInvalidCursor:
+			utils.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		case utils.ErrInvalidThread:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, thread)
+}
+
+func (h *WebSocketHandler) GetEditHistory(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, valid := claimsRaw.(*utils.JWTCLAIMS)
+	if !valid {
+		utils.WriteError(w, http.StatusUnauthorized, "invalid jwt")
+		return
+	}
+
+	params := mux.Vars(r)
+	paramsGroupid, _ := strconv.Atoi(params["groupId"])
+	paramsChatid, _ := strconv.Atoi(params["chatId"])
+
+	_, err := h.usecase.GetMemberId(claims.UserID, uint(paramsGroupid))
+	if err != nil {
+		switch err {
+		case utils.ErrNotMember:
+			utils.WriteError(w, http.StatusForbidden, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	history, err := h.usecase.GetEditHistory(uint(paramsGroupid), uint(paramsChatid))
+	if err != nil {
+		switch err {
+		case utils.ErrChatNotFound:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, history)
+}
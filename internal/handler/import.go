@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"api_chat_ws/dto"
+	"api_chat_ws/helper/middleware"
+	"api_chat_ws/helper/utils"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+const maxImportSize = 1 << 20
+
+// ImportMembers menerima csv lewat form field "file" atau langsung sebagai body (text/csv).
+// ?dry_run=true hanya mengembalikan laporan validasi tanpa menyimpan.
+func (h *WebSocketHandler) ImportMembers(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, valid := claimsRaw.(*utils.JWTCLAIMS)
+	if !valid {
+		utils.WriteError(w, http.StatusUnauthorized, "invalid jwt")
+		return
+	}
+
+	params := mux.Vars(r)
+	paramsGroupid, _ := strconv.Atoi(params["groupId"])
+
+	memberId, err := h.usecase.GetMemberId(claims.UserID, uint(paramsGroupid))
+	if err != nil {
+		switch err {
+		case utils.ErrNotMember:
+			utils.WriteError(w, http.StatusForbidden, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+	var file io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		upload, _, err := r.FormFile("file")
+		if err != nil {
+			utils.WriteError(w, http.StatusBadRequest, "invalid file")
+			return
+		}
+		defer upload.Close()
+		file = upload
+	}
+
+	req := dto.ImportMembersReq{
+		AdminId: memberId,
+		GroupId: uint(paramsGroupid),
+		DryRun:  r.URL.Query().Get("dry_run") == "true",
+		File:    file,
+	}
+	report, systems, err := h.usecase.ImportMembers(&req)
+	if err != nil {
+		switch err {
+		case utils.ErrForbidden, utils.ErrGroupArchived:
+			utils.WriteError(w, http.StatusForbidden, err.Error())
+			return
+		case utils.ErrInvalidCSV, utils.ErrEmptyImport, utils.ErrImportTooLarge, utils.ErrDirectChat:
+			utils.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		case utils.ErrGroupNotFound:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	for _, system := range systems {
+		h.broadcast(req.GroupId, system)
+	}
+
+	utils.WriteJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"api_chat_ws/dto"
+	"api_chat_ws/helper/middleware"
+	"api_chat_ws/helper/utils"
+	"api_chat_ws/model"
+	"api_chat_ws/ws"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+func (h *WebSocketHandler) Mute(w http.ResponseWriter, r *http.Request) {
+	h.sanction(w, r, model.SanctionMute)
+}
+
+func (h *WebSocketHandler) Ban(w http.ResponseWriter, r *http.Request) {
+	h.sanction(w, r, model.SanctionBan)
+}
+
+func (h *WebSocketHandler) Unmute(w http.ResponseWriter, r *http.Request) {
+	h.liftSanction(w, r, model.SanctionMute)
+}
+
+func (h *WebSocketHandler) Unban(w http.ResponseWriter, r *http.Request) {
+	h.liftSanction(w, r, model.SanctionBan)
+}
+
+func (h *WebSocketHandler) sanction(w http.ResponseWriter, r *http.Request, sanctionType string) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, valid := claimsRaw.(*utils.JWTCLAIMS)
+	if !valid {
+		utils.WriteError(w, http.StatusUnauthorized, "invalid jwt")
+		return
+	}
+
+	params := mux.Vars(r)
+	paramsGroupid, _ := strconv.Atoi(params["groupId"])
+
+	var req dto.SanctionReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+
+	memberId, err := h.usecase.GetMemberId(claims.UserID, uint(paramsGroupid))
+	if err != nil {
+		switch err {
+		case utils.ErrNotMember:
+			utils.WriteError(w, http.StatusForbidden, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	req.AdminId = memberId
+	req.GroupId = uint(paramsGroupid)
+	req.Type = sanctionType
+	res, system, err := h.usecase.Sanction(&req)
+	if err != nil {
+		switch err {
+		case utils.ErrForbidden, utils.ErrRoleTooHigh, utils.ErrGroupArchived:
+			utils.WriteError(w, http.StatusForbidden, err.Error())
+			return
+		case utils.ErrInvalidDuration, utils.ErrDirectChat:
+			utils.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		case utils.ErrUserNotFound, utils.ErrTargetNotMember:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		case utils.ErrGroupNotFound:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	event := ws.NewEvent(ws.EventSanction, res)
+	// koneksi di grup yang di-ban sudah menerima event lewat Kick
+	if sanctionType == model.SanctionBan {
+		h.hub.Kick(res.GroupId, res.UserId, event)
+		h.hub.SendToUserExcept(res.UserId, res.GroupId, event)
+	} else {
+		h.hub.SendToUser(res.UserId, event)
+	}
+	h.broadcast(res.GroupId, system)
+
+	utils.WriteJSON(w, http.StatusOK, res)
+}
+
+func (h *WebSocketHandler) liftSanction(w http.ResponseWriter, r *http.Request, sanctionType string) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, valid := claimsRaw.(*utils.JWTCLAIMS)
+	if !valid {
+		utils.WriteError(w, http.StatusUnauthorized, "invalid jwt")
+		return
+	}
+
+	params := mux.Vars(r)
+	paramsGroupid, _ := strconv.Atoi(params["groupId"])
+	paramsUserid, err := strconv.Atoi(params["userId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	memberId, err := h.usecase.GetMemberId(claims.UserID, uint(paramsGroupid))
+	if err != nil {
+		switch err {
+		case utils.ErrNotMember:
+			utils.WriteError(w, http.StatusForbidden, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	res, err := h.usecase.LiftSanction(&dto.LiftSanctionReq{
+		AdminId: memberId,
+		GroupId: uint(paramsGroupid),
+		Type:    sanctionType,
+		UserId:  uint(paramsUserid),
+	})
+	if err != nil {
+		switch err {
+		case utils.ErrForbidden, utils.ErrRoleTooHigh, utils.ErrGroupArchived:
+			utils.WriteError(w, http.StatusForbidden, err.Error())
+			return
+		case utils.ErrDirectChat:
+			utils.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		case utils.ErrNoSanction:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		case utils.ErrGroupNotFound:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	h.hub.SendToUser(res.UserId, ws.NewEvent(ws.EventSanction, res))
+
+	utils.WriteJSON(w, http.StatusOK, res)
+}
+
+func (h *WebSocketHandler) GetSanctions(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, valid := claimsRaw.(*utils.JWTCLAIMS)
+	if !valid {
+		utils.WriteError(w, http.StatusUnauthorized, "invalid jwt")
+		return
+	}
+
+	params := mux.Vars(r)
+	paramsGroupid, _ := strconv.Atoi(params["groupId"])
+
+	memberId, err := h.usecase.GetMemberId(claims.UserID, uint(paramsGroupid))
+	if err != nil {
+		switch err {
+		case utils.ErrNotMember:
+			utils.WriteError(w, http.StatusForbidden, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	sanctions, err := h.usecase.GetSanctions(memberId, uint(paramsGroupid))
+	if err != nil {
+		switch err {
+		case utils.ErrForbidden:
+			utils.WriteError(w, http.StatusForbidden, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, sanctions)
+}
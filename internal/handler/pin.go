@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"api_chat_ws/dto"
+	"api_chat_ws/helper/middleware"
+	"api_chat_ws/helper/utils"
+	"api_chat_ws/ws"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+func (h *WebSocketHandler) GetPins(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, valid := claimsRaw.(*utils.JWTCLAIMS)
+	if !valid {
+		utils.WriteError(w, http.StatusUnauthorized, "invalid jwt")
+		return
+	}
+
+	params := mux.Vars(r)
+	paramsGroupid, _ := strconv.Atoi(params["groupId"])
+
+	memberId, err := h.usecase.GetMemberId(claims.UserID, uint(paramsGroupid))
+	if err != nil {
+		switch err {
+		case utils.ErrNotMember:
+			utils.WriteError(w, http.StatusForbidden, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	pins, err := h.usecase.GetPins(memberId, uint(paramsGroupid))
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, pins)
+}
+
+func (h *WebSocketHandler) PinChat(w http.ResponseWriter, r *http.Request) {
+	h.setPinned(w, r, true)
+}
+
+func (h *WebSocketHandler) UnpinChat(w http.ResponseWriter, r *http.Request) {
+	h.setPinned(w, r, false)
+}
+
+func (h *WebSocketHandler) setPinned(w http.ResponseWriter, r *http.Request, pin bool) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, valid := claimsRaw.(*utils.JWTCLAIMS)
+	if !valid {
+		utils.WriteError(w, http.StatusUnauthorized, "invalid jwt")
+		return
+	}
+
+	params := mux.Vars(r)
+	paramsGroupid, _ := strconv.Atoi(params["groupId"])
+	paramsChatid, _ := strconv.Atoi(params["chatId"])
+
+	memberId, err := h.usecase.GetMemberId(claims.UserID, uint(paramsGroupid))
+	if err != nil {
+		switch err {
+		case utils.ErrNotMember:
+			utils.WriteError(w, http.StatusForbidden, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	event, system, err := h.usecase.SetPinned(&dto.PinReq{
+		MemberId: memberId,
+		GroupId:  uint(paramsGroupid),
+		ChatId:   uint(paramsChatid),
+		Pin:      pin,
+	})
+	if err != nil {
+		switch err {
+		case utils.ErrForbidden, utils.ErrGroupArchived:
+			utils.WriteError(w, http.StatusForbidden, err.Error())
+			return
+		case utils.ErrChatNotFound:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		case utils.ErrAlreadyPinned, utils.ErrNotPinned, utils.ErrPinLimit:
+			utils.WriteError(w, http.StatusConflict, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	name := ws.EventUnpin
+	if pin {
+		name = ws.EventPin
+	}
+	h.broadcast(uint(paramsGroupid), ws.NewEvent(name, event))
+	h.broadcast(uint(paramsGroupid), system)
+
+	utils.WriteJSON(w, http.StatusOK, event)
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"api_chat_ws/dto"
+	"api_chat_ws/helper/utils"
+	"api_chat_ws/model"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func (r *chatRepo) GetDeletedGroup(groupId uint) (*model.ChatGroup, error) {
+	var group model.ChatGroup
+	err := r.db.Unscoped().Model(&model.ChatGroup{}).Where("id = ? AND deleted_at IS NOT NULL", groupId).First(&group).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, utils.ErrGroupNotDeleted
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &group, nil
+}
+
+func (r *chatRepo) RestoreGroup(groupId uint) error {
+	return r.db.Unscoped().Model(&model.ChatGroup{}).Where("id = ?", groupId).Update("deleted_at", nil).Error
+}
+
+func (r *chatRepo) GetPurgeableGroups(deletedBefore time.Time) ([]uint, error) {
+	var ids []uint
+	err := r.db.Unscoped().Model(&model.ChatGroup{}).Where("deleted_at IS NOT NULL AND deleted_at < ?", deletedBefore).Pluck("id", &ids).Error
+	return ids, err
+}
+
+// HardDeleteGroup menghapus grup permanen, member dan data lain ikut terhapus lewat cascade
+func (r *chatRepo) HardDeleteGroup(groupId uint) error {
+	return r.db.Unscoped().Model(&model.ChatGroup{}).Where("id = ?", groupId).Delete(&model.ChatGroup{}).Error
+}
+
+// SearchChats mencari di semua grup milik user, termasuk yang diarsipkan
+func (r *chatRepo) SearchChats(userId uint, query string, groupId uint, limit int) ([]dto.SearchResult, error) {
+	groups := r.db.Model(&model.GroupMember{}).
+		Select("group_members.group_id").
+		Joins("JOIN chat_groups ON chat_groups.id = group_members.group_id AND chat_groups.deleted_at IS NULL").
+		Where("group_members.user_id = ?", userId)
+
+	search := r.db.Model(&model.Chat{}).
+		Select("chats.group_id, chat_groups.name AS group_name, chats.id AS chat_id, chats.group_member_id AS member_id, chats.message, chats.created_at").
+		Joins("JOIN chat_groups ON chat_groups.id = chats.group_id").
+		Where("chats.group_id IN (?) AND chats.message LIKE ?", groups, "%"+likeEscaper.Replace(query)+"%")
+	// pesan yang disembunyikan user di grup mana pun tidak ikut dicari
+	search = search.Where("chats.deleted_at IS NULL AND chats.id NOT IN (?)", r.db.Model(&model.HiddenChat{}).
+		Select("hidden_chats.chat_id").
+		Joins("JOIN group_members ON group_members.id = hidden_chats.member_id").
+		Where("group_members.user_id = ?", userId))
+	if groupId != 0 {
+		search = search.Where("chats.group_id = ?", groupId)
+	}
+
+	var results []dto.SearchResult
+	if err := search.Order("chats.created_at DESC").Limit(limit).Scan(&results).Error; err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"api_chat_ws/dto"
+	"api_chat_ws/helper/utils"
+	"api_chat_ws/model"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func (r *chatRepo) CreateAttachment(attachment *model.Attachment) error {
+	return r.db.Create(attachment).Error
+}
+
+func (r *chatRepo) GetAttachment(attachmentId uint) (*model.Attachment, error) {
+	var attachment model.Attachment
+	err := r.db.Model(&model.Attachment{}).Where("id = ?", attachmentId).First(&attachment).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, utils.ErrAttachmentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &attachment, nil
+}
+
+func (r *chatRepo) GetAttachments(attachmentIds []uint) ([]model.Attachment, error) {
+	var attachments []model.Attachment
+	err := r.db.Model(&model.Attachment{}).Where("id IN ?", attachmentIds).Order("id").Find(&attachments).Error
+	return attachments, err
+}
+
+func (r *chatRepo) GetChatAttachments(chatId uint) ([]model.Attachment, error) {
+	var attachments []model.Attachment
+	err := r.db.Model(&model.Attachment{}).Where("chat_id = ?", chatId).Order("id").Find(&attachments).Error
+	return attachments, err
+}
+
+// GetOrphanAttachments mengambil lampiran yang tidak dipakai sampai batas waktu, yang dilepas dari
+// pesan yang dihapus (member_id dikosongkan), atau yang grupnya sudah dihapus permanen
+func (r *chatRepo) GetOrphanAttachments(before time.Time, limit int) ([]model.Attachment, error) {
+	var attachments []model.Attachment
+	err := r.db.Model(&model.Attachment{}).
+		Where("(chat_id IS NULL AND (created_at < ? OR member_id IS NULL)) OR group_id NOT IN (SELECT id FROM chat_groups)", before).
+		Order("id").Limit(limit).Find(&attachments).Error
+	return attachments, err
+}
+
+func (r *chatRepo) DeleteAttachments(attachmentIds []uint) error {
+	if len(attachmentIds) == 0 {
+		return nil
+	}
+	return r.db.Where("id IN ?", attachmentIds).Delete(&model.Attachment{}).Error
+}
+
+func toAttachmentResponse(a *model.Attachment) dto.AttachmentResponse {
+	return dto.AttachmentResponse{
+		ID:          a.ID,
+		Name:        a.Name,
+		ContentType: a.ContentType,
+		Size:        a.Size,
+	}
+}
+
+// linkAttachments menautkan lampiran yang belum dipakai ke pesan, gagal jika salah satunya sudah diambil pesan lain
+func linkAttachments(tx *gorm.DB, chatId uint, attachments []model.Attachment) error {
+	ids := make([]uint, 0, len(attachments))
+	for _, a := range attachments {
+		ids = append(ids, a.ID)
+	}
+	result := tx.Model(&model.Attachment{}).Where("id IN ? AND chat_id IS NULL", ids).Update("chat_id", chatId)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected != int64(len(ids)) {
+		return utils.ErrInvalidAttachment
+	}
+	return nil
+}
+
+// attachAttachments mengisi daftar lampiran tiap pesan, url unduhan diminta terpisah karena bertanda tangan
+func (r *chatRepo) attachAttachments(response []dto.ResponseChat) error {
+	chatIds := make([]uint, 0, len(response))
+	index := make(map[uint]int, len(response))
+	for i := range response {
+		if response[i].Deleted || response[i].Type != model.ChatTypeMessage {
+			continue
+		}
+		chatIds = append(chatIds, response[i].ID)
+		index[response[i].ID] = i
+	}
+	if len(chatIds) == 0 {
+		return nil
+	}
+
+	var attachments []model.Attachment
+	if err := r.db.Model(&model.Attachment{}).Where("chat_id IN ?", chatIds).Order("id").Find(&attachments).Error; err != nil {
+		return err
+	}
+	for i := range attachments {
+		pos := index[*attachments[i].ChatID]
+		response[pos].Attachments = append(response[pos].Attachments, toAttachmentResponse(&attachments[i]))
+	}
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"api_chat_ws/dto"
+	"api_chat_ws/model"
+)
+
+func (r *chatRepo) CreateAudit(audit *model.GroupAudit) error {
+	return r.db.Model(&model.GroupAudit{}).Create(audit).Error
+}
+
+func (r *chatRepo) GetAudits(filter *dto.AuditFilter) ([]model.GroupAudit, int64, error) {
+	query := r.db.Model(&model.GroupAudit{}).Where("group_id = ?", filter.GroupId)
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.ActorId != 0 {
+		query = query.Where("actor_id = ?", filter.ActorId)
+	}
+	if filter.TargetId != 0 {
+		query = query.Where("target_id = ?", filter.TargetId)
+	}
+	if filter.Since != nil {
+		query = query.Where("created_at >= ?", *filter.Since)
+	}
+	if filter.Until != nil {
+		query = query.Where("created_at < ?", *filter.Until)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var audits []model.GroupAudit
+	err := query.Order("created_at DESC, id DESC").
+		Offset((filter.Page - 1) * filter.Limit).
+		Limit(filter.Limit).
+		Find(&audits).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return audits, total, nil
+}
@@ -4,31 +4,113 @@ import (
 	"api_chat_ws/dto"
 	"api_chat_ws/helper/utils"
 	"api_chat_ws/model"
+	"encoding/json"
 	"errors"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ChatRepo interface {
-	CreateGroup(req *dto.CreateGroupReq) error
+	CreateGroup(req *dto.CreateGroupReq) (*model.ChatGroup, error)
 	UpdateGroup(req *dto.UpdateGroupReq) error
 	DeleteGroup(groupId uint) error
 	AddMember(req *dto.AddMemberReq) error
-	RemoveMember(req []uint) error
-	ExitGroup(memberId uint) error
+	RemoveMember(groupId uint, memberIds []uint) error
+	ExitGroup(memberId uint) (*model.GroupMember, error)
 	UpdateRoleUser(memberId uint, role string) error
+	TransferOwnership(ownerId, memberId uint) error
+
+	GetGroup(groupId uint) (*model.ChatGroup, error)
+	GetMember(memberId uint) (*model.GroupMember, error)
+	GetMembersByIds(groupId uint, memberIds []uint) ([]model.GroupMember, error)
+	GetMemberList(groupId uint) ([]model.GroupMember, error)
+	SetNickname(memberId uint, nickname string) error
+	GetUser(userId uint) (*model.User, error)
+	FindDirectGroup(key string) (*model.ChatGroup, error)
+	CreateDirectGroup(key string, userIds []uint) (*model.ChatGroup, error)
+	GetInbox(userId uint, archived bool) ([]dto.InboxItem, error)
+
+	GetDeletedGroup(groupId uint) (*model.ChatGroup, error)
+	RestoreGroup(groupId uint) error
+	GetPurgeableGroups(deletedBefore time.Time) ([]uint, error)
+	HardDeleteGroup(groupId uint) error
+	SearchChats(userId uint, query string, groupId uint, limit int) ([]dto.SearchResult, error)
+
+	GetMemberByUser(groupId, userId uint) (*model.GroupMember, error)
+
+	CreateSanction(sanction *model.GroupSanction, removeMemberId uint) error
+	LiftSanction(groupId, userId uint, sanctionType string) (*model.GroupSanction, error)
+	GetActiveSanction(groupId, userId uint, sanctionType string) (*model.GroupSanction, error)
+	GetActiveSanctions(groupId uint) ([]model.GroupSanction, error)
+	GetBannedUsers(groupId uint, userIds []uint) ([]uint, error)
+	ExpireSanctions(now time.Time) ([]model.GroupSanction, error)
+
+	UpdateGroupSettings(groupId uint, updated map[string]interface{}) error
+	GetRetentionGroups(defaultDays int) ([]model.ChatGroup, error)
+	PurgeChatBatch(groupId uint, before time.Time, limit int) (int, error)
+
+	CreateAudit(audit *model.GroupAudit) error
+	GetAudits(filter *dto.AuditFilter) ([]model.GroupAudit, int64, error)
+
+	GetPermission(groupId uint, role, perm string) (*bool, error)
+	GetPermissions(groupId uint) ([]model.GroupPermission, error)
+	SetPermissions(groupId uint, role string, permissions map[string]bool) error
 
-	IsMemberAdmin(memberId uint) (bool, error)
 	GetMemberId(id, groupId uint) (uint, error)
-	LoadGroupChat(groupId uint) ([]dto.ResponseChat, error)
+	GetChatPage(filter *dto.ChatFilter, cursor *dto.ChatCursor, older bool, limit int) ([]dto.ResponseChat, error)
 	GetGroupMembers(groupID uint) ([]uint, error)
 
-	CreateChat(memberId, groupId uint, message string, status []dto.MemberStatus) (*dto.ResponseChat, error)
-	UpdateChat(chatId, memberId uint, message string) (*dto.ResponseChat, error)
-	DeleteChat(memberId, id uint) (*dto.ResponseChat, error)
-
-	UpdateStatusChat(memberId uint) error
+	CreateChat(chat *model.Chat, status []dto.MemberStatus) (*dto.ResponseChat, error)
+	CreateSystemChat(groupId uint, message string, info *dto.SystemInfo) (*dto.ResponseChat, error)
+	GetUsernames(userIds []uint) (map[uint]string, error)
+	FindMembersByUsernames(groupId uint, usernames []string) ([]model.GroupMember, error)
+	GetLastChatTime(memberId uint) (*time.Time, error)
+	GetChat(chatId uint) (*model.Chat, error)
+	GetChatResponse(chatId, viewerId uint) (*dto.ResponseChat, error)
+	GetThreadRoots(groupId, viewerId uint, limit int) ([]dto.ResponseChat, error)
+	GetThreadUnread(memberId uint, rootIds []uint) (map[uint]int64, error)
+	MarkThreadRead(memberId, rootId uint) error
+
+	AddReaction(reaction *model.Reaction) error
+	RemoveReaction(chatId, memberId uint, emoji string) (bool, error)
+	CountReaction(chatId uint, emoji string) (int64, error)
+	CountReactionKinds(chatId uint) (int64, error)
+	UpdateChat(chat *model.Chat, message string) error
+	GetRevisions(chatId uint) ([]model.ChatRevision, error)
+	DeleteChat(chat *model.Chat, deletedBy uint) error
+	HideChat(chatId, memberId uint) error
+
+	PinChat(groupId, chatId, memberId uint, limit int) (*time.Time, error)
+	UnpinChat(chatId uint) error
+	GetPinnedChats(groupId, viewerId uint) ([]dto.ResponseChat, error)
+
+	CreateAttachment(attachment *model.Attachment) error
+	GetAttachment(attachmentId uint) (*model.Attachment, error)
+	GetAttachments(attachmentIds []uint) ([]model.Attachment, error)
+	GetChatAttachments(chatId uint) ([]model.Attachment, error)
+	GetOrphanAttachments(before time.Time, limit int) ([]model.Attachment, error)
+	DeleteAttachments(attachmentIds []uint) error
+
+	UpdateStatusChat(memberId uint, topicId *uint) error
+
+	GetTopic(topicId uint) (*model.Topic, error)
+	GetTopics(groupId uint) ([]model.Topic, error)
+	CreateTopic(topic *model.Topic) error
+	RenameTopic(topicId uint, name string) error
+	GetTopicUnread(memberId uint) (map[uint]int64, error)
+
+	FindUsersByIdentifiers(identifiers []string) ([]model.User, error)
+	GetMemberUserIds(groupId uint, userIds []uint) ([]uint, error)
+	ImportMembers(members []model.GroupMember) error
+
+	CountMembers(groupId uint) (int64, error)
+	CountGroupsCreated(userId uint) (int64, error)
+	CountGroupsJoined(userIds []uint) (map[uint]int64, error)
 }
 
 type chatRepo struct {
@@ -40,44 +122,64 @@ func NewChatRepository(db *gorm.DB) ChatRepo {
 }
 
 // write group & member
-func (r *chatRepo) CreateGroup(req *dto.CreateGroupReq) error {
+func (r *chatRepo) CreateGroup(req *dto.CreateGroupReq) (*model.ChatGroup, error) {
 	tx := r.db.Begin()
 
 	newGroup := model.ChatGroup{
-		Name:        req.Name,
-		Description: req.Name,
+		Name:           req.Name,
+		Description:    req.Desc,
+		IsAnnouncement: req.Announcement,
+		CreatedBy:      req.UserId,
 	}
 	if err := tx.Create(&newGroup).Error; err != nil {
 		tx.Rollback()
-		return err
+		return nil, err
 	}
 
-	admin := model.GroupMember{
+	owner := model.GroupMember{
 		GroupID: newGroup.ID,
 		UserID:  req.UserId,
-		Role:    "admin",
+		Role:    model.RoleOwner,
 	}
-	if err := tx.Create(&admin).Error; err != nil {
+	if err := tx.Create(&owner).Error; err != nil {
 		tx.Rollback()
-		return err
+		return nil, err
 	}
 
 	if err := tx.Commit().Error; err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return &newGroup, nil
 }
 
 func (r *chatRepo) UpdateGroup(req *dto.UpdateGroupReq) error {
 	updated := make(map[string]interface{})
 
 	if req.Desc != "" {
-		updated["desc"] = req.Desc
+		updated["description"] = req.Desc
 	}
 	if req.Name != "" {
 		updated["name"] = req.Name
 	}
+	if req.Announcement != nil {
+		updated["is_announcement"] = *req.Announcement
+	}
+	if req.SlowMode != nil {
+		updated["slow_mode_seconds"] = *req.SlowMode
+	}
+	if req.ForwardAuthor != nil {
+		updated["forward_author"] = *req.ForwardAuthor
+	}
+	if req.AttachmentMaxMB != nil {
+		updated["attachment_max_mb"] = *req.AttachmentMaxMB
+	}
+	if req.AttachmentTypes != nil {
+		updated["attachment_types"] = strings.Join(*req.AttachmentTypes, ",")
+	}
+	if len(updated) == 0 {
+		return nil
+	}
 	return r.db.Model(&model.ChatGroup{}).Where("id = ?", req.GroupId).Updates(updated).Error
 }
 
@@ -92,71 +194,483 @@ func (r *chatRepo) AddMember(req *dto.AddMemberReq) error {
 		newMembers = append(newMembers, model.GroupMember{
 			GroupID: req.GroupId,
 			UserID:  nm,
-			Role:    "member",
+			Role:    model.RoleMember,
 		})
 	}
 
 	return r.db.Model(&model.GroupMember{}).CreateInBatches(&newMembers, 2).Error
 }
 
-func (r *chatRepo) RemoveMember(req []uint) error {
-	return r.db.Model(&model.GroupMember{}).Where("id IN ?", req).Delete(&model.GroupMember{}).Error
+func (r *chatRepo) RemoveMember(groupId uint, memberIds []uint) error {
+	return r.db.Model(&model.GroupMember{}).Where("id IN ? AND group_id = ?", memberIds, groupId).Delete(&model.GroupMember{}).Error
 }
 
-func (r *chatRepo) ExitGroup(memberId uint) error {
-	return r.db.Model(&model.GroupMember{}).Where("id = ?", memberId).Delete(&model.GroupMember{}).Error
+// owner terakhir yang keluar digantikan member dengan role tertinggi yang paling lama bergabung,
+// grup dihapus jika tidak ada member tersisa. Member pengganti dikembalikan jika ada.
+func (r *chatRepo) ExitGroup(memberId uint) (*model.GroupMember, error) {
+	tx := r.db.Begin()
+	var successor *model.GroupMember
+
+	var member model.GroupMember
+	if err := tx.Model(&model.GroupMember{}).Where("id = ?", memberId).First(&member).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Model(&model.GroupMember{}).Where("id = ?", memberId).Delete(&model.GroupMember{}).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if member.Role == model.RoleOwner {
+		var owners int64
+		if err := tx.Model(&model.GroupMember{}).Where("group_id = ? AND role = ?", member.GroupID, model.RoleOwner).Count(&owners).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		if owners == 0 {
+			var remaining []model.GroupMember
+			if err := tx.Model(&model.GroupMember{}).Where("group_id = ?", member.GroupID).Order("created_at, id").Find(&remaining).Error; err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+
+			if len(remaining) == 0 {
+				if err := tx.Model(&model.ChatGroup{}).Where("id = ?", member.GroupID).Delete(&model.ChatGroup{}).Error; err != nil {
+					tx.Rollback()
+					return nil, err
+				}
+			} else {
+				successor = &remaining[0]
+				for i := range remaining[1:] {
+					if model.RoleRank(remaining[i+1].Role) > model.RoleRank(successor.Role) {
+						successor = &remaining[i+1]
+					}
+				}
+				if err := tx.Model(&model.GroupMember{}).Where("id = ?", successor.ID).Update("role", model.RoleOwner).Error; err != nil {
+					tx.Rollback()
+					return nil, err
+				}
+				successor.Role = model.RoleOwner
+			}
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return successor, nil
 }
 
 func (r *chatRepo) UpdateRoleUser(memberId uint, role string) error {
 	return r.db.Model(&model.GroupMember{}).Where("id = ?", memberId).Update("role", role).Error
 }
 
-func (r *chatRepo) IsMemberAdmin(memberId uint) (bool, error) {
-	var count int64
-	if err := r.db.Model(&model.GroupMember{}).Where("id = ? AND role = ?", memberId, "admin").Count(&count).Error; err != nil {
-		return false, err
+func (r *chatRepo) TransferOwnership(ownerId, memberId uint) error {
+	tx := r.db.Begin()
+
+	if err := tx.Model(&model.GroupMember{}).Where("id = ?", memberId).Update("role", model.RoleOwner).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Model(&model.GroupMember{}).Where("id = ?", ownerId).Update("role", model.RoleAdmin).Error; err != nil {
+		tx.Rollback()
+		return err
 	}
 
-	return count > 0, nil
+	return tx.Commit().Error
 }
 
-func (r *chatRepo) LoadGroupChat(groupId uint) ([]dto.ResponseChat, error) {
-	var chats []model.Chat
-	if err := r.db.Model(&model.Chat{}).Select("id AS chat_id,group_member_id  AS member_id, message, created_at").Preload("ChatRead").Where("group_id = ?", groupId).Find(&chats).Order("-created_at").Error; err != nil {
+func (r *chatRepo) GetGroup(groupId uint) (*model.ChatGroup, error) {
+	var group model.ChatGroup
+	err := r.db.Model(&model.ChatGroup{}).Where("id = ?", groupId).First(&group).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, utils.ErrGroupNotFound
+	}
+	if err != nil {
 		return nil, err
 	}
-	response := make([]dto.ResponseChat, 0, len(chats))
-	for _, c := range chats {
-		responseChatRead := make([]dto.StatusChatRead, len(c.ReadStatus))
-		for _, cr := range c.ReadStatus {
-			responseChatRead = append(responseChatRead, dto.StatusChatRead{
-				MemberId: cr.MemberId,
-				IsRead:   cr.IsRead,
-			})
+
+	return &group, nil
+}
+
+func (r *chatRepo) GetMember(memberId uint) (*model.GroupMember, error) {
+	var member model.GroupMember
+	err := r.db.Model(&model.GroupMember{}).Where("id = ?", memberId).First(&member).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, utils.ErrNotMember
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &member, nil
+}
+
+// GetMemberByUser mengembalikan nil jika user bukan member grup
+func (r *chatRepo) GetMemberByUser(groupId, userId uint) (*model.GroupMember, error) {
+	var member model.GroupMember
+	err := r.db.Model(&model.GroupMember{}).Where("group_id = ? AND user_id = ?", groupId, userId).First(&member).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &member, nil
+}
+
+func (r *chatRepo) GetMembersByIds(groupId uint, memberIds []uint) ([]model.GroupMember, error) {
+	var members []model.GroupMember
+	if err := r.db.Model(&model.GroupMember{}).Where("group_id = ? AND id IN ?", groupId, memberIds).Find(&members).Error; err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+func (r *chatRepo) GetMemberList(groupId uint) ([]model.GroupMember, error) {
+	var members []model.GroupMember
+	if err := r.db.Model(&model.GroupMember{}).Preload("User").Where("group_id = ?", groupId).Order("created_at, id").Find(&members).Error; err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+func (r *chatRepo) SetNickname(memberId uint, nickname string) error {
+	return r.db.Model(&model.GroupMember{}).Where("id = ?", memberId).Update("nickname", nickname).Error
+}
+
+func (r *chatRepo) GetUser(userId uint) (*model.User, error) {
+	var user model.User
+	err := r.db.Model(&model.User{}).Select("id", "username", "email").Where("id = ?", userId).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, utils.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// direct message
+func (r *chatRepo) FindDirectGroup(key string) (*model.ChatGroup, error) {
+	var group model.ChatGroup
+	err := r.db.Model(&model.ChatGroup{}).Where("direct_key = ?", key).First(&group).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &group, nil
+}
+
+func (r *chatRepo) CreateDirectGroup(key string, userIds []uint) (*model.ChatGroup, error) {
+	tx := r.db.Begin()
+
+	newGroup := model.ChatGroup{
+		Type:      model.GroupTypeDirect,
+		DirectKey: &key,
+	}
+	if err := tx.Create(&newGroup).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	members := make([]model.GroupMember, 0, len(userIds))
+	for _, id := range userIds {
+		members = append(members, model.GroupMember{
+			GroupID: newGroup.ID,
+			UserID:  id,
+			Role:    model.RoleMember,
+		})
+	}
+	if err := tx.Create(&members).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return &newGroup, nil
+}
+
+// inbox
+// GetInbox mengambil grup aktif, atau hanya grup yang diarsipkan jika archived true
+func (r *chatRepo) GetInbox(userId uint, archived bool) ([]dto.InboxItem, error) {
+	groups := r.db.Model(&model.ChatGroup{}).Select("id").Where("archived_at IS NULL")
+	if archived {
+		groups = r.db.Model(&model.ChatGroup{}).Select("id").Where("archived_at IS NOT NULL")
+	}
+
+	var members []model.GroupMember
+	if err := r.db.Model(&model.GroupMember{}).Preload("ChatGroup").Where("user_id = ? AND group_id IN (?)", userId, groups).Find(&members).Error; err != nil {
+		return nil, err
+	}
+
+	if len(members) == 0 {
+		return []dto.InboxItem{}, nil
+	}
+
+	memberIds := make([]uint, 0, len(members))
+	directIds := make([]uint, 0)
+	for _, m := range members {
+		memberIds = append(memberIds, m.ID)
+		if m.ChatGroup.Type == model.GroupTypeDirect {
+			directIds = append(directIds, m.GroupID)
+		}
+	}
+
+	peers := make(map[uint]model.GroupMember, len(directIds))
+	if len(directIds) > 0 {
+		var rows []model.GroupMember
+		if err := r.db.Model(&model.GroupMember{}).Preload("User").Where("group_id IN ? AND user_id <> ?", directIds, userId).Find(&rows).Error; err != nil {
+			return nil, err
+		}
+		for _, peer := range rows {
+			peers[peer.GroupID] = peer
+		}
+	}
+
+	lastMessages, err := r.lastVisibleChats(memberIds)
+	if err != nil {
+		return nil, err
+	}
+	unread, err := r.countUnread(memberIds)
+	if err != nil {
+		return nil, err
+	}
+	unreadMentions, err := r.countUnreadMentions(memberIds)
+	if err != nil {
+		return nil, err
+	}
+
+	inbox := make([]dto.InboxItem, 0, len(members))
+	for _, m := range members {
+		item := dto.InboxItem{
+			GroupResponse: dto.GroupResponse{
+				GroupId:         m.GroupID,
+				Type:            m.ChatGroup.Type,
+				Name:            m.ChatGroup.Name,
+				Desc:            m.ChatGroup.Description,
+				Announcement:    m.ChatGroup.IsAnnouncement,
+				SlowMode:        m.ChatGroup.SlowModeSeconds,
+				ForwardAuthor:   m.ChatGroup.ForwardAuthor,
+				AttachmentMaxMB: m.ChatGroup.AttachmentMaxMB,
+				AttachmentTypes: utils.SplitList(m.ChatGroup.AttachmentTypes),
+				AvatarKey:       m.ChatGroup.AvatarKey,
+				BannerKey:       m.ChatGroup.BannerKey,
+			},
+			UnreadCount:   unread[m.ID],
+			UnreadMention: unreadMentions[m.ID],
+		}
+
+		if peer, ok := peers[m.GroupID]; ok {
+			item.Name = peer.User.Username
+			item.Peer = &dto.UserBrief{
+				UserId:   peer.UserID,
+				Username: peer.User.Username,
+			}
+		}
+		if last, ok := lastMessages[m.ID]; ok {
+			item.LastMessage = last.Message
+			item.LastMessageAt = &last.CreatedAt
 		}
-		response = append(response, dto.ResponseChat{
-			ID:        c.ID,
-			MemberId:  *c.GroupMemberID,
-			Message:   c.Message,
-			CreatedAt: c.CreatedAt,
-			Status:    responseChatRead,
+
+		inbox = append(inbox, item)
+	}
+
+	sort.SliceStable(inbox, func(i, j int) bool {
+		a, b := inbox[i].LastMessageAt, inbox[j].LastMessageAt
+		if a == nil || b == nil {
+			return b == nil && a != nil
+		}
+		return a.After(*b)
+	})
+
+	return inbox, nil
+}
+
+// lastVisibleChats mengambil pesan terakhir yang masih terlihat oleh tiap member dalam satu query, key member id.
+// Pesan yang sudah dihapus dan balasan thread yang tidak tampil di timeline utama dilewati, sama seperti whereTimeline.
+func (r *chatRepo) lastVisibleChats(memberIds []uint) (map[uint]model.Chat, error) {
+	var rows []struct {
+		MemberID  uint
+		Message   string
+		CreatedAt time.Time
+	}
+	err := r.db.Raw(`SELECT group_members.id AS member_id, chats.message, chats.created_at
+		FROM group_members
+		JOIN chats ON chats.id = (
+			SELECT c.id FROM chats c
+			WHERE c.group_id = group_members.group_id
+				AND c.deleted_at IS NULL
+				AND (c.thread_root_id IS NULL OR c.also_sent = ?)
+				AND c.id NOT IN (SELECT chat_id FROM hidden_chats WHERE member_id = group_members.id)
+			ORDER BY c.created_at DESC, c.id DESC
+			LIMIT 1
+		)
+		WHERE group_members.id IN ?`, true, memberIds).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	last := make(map[uint]model.Chat, len(rows))
+	for _, row := range rows {
+		last[row.MemberID] = model.Chat{Message: row.Message, CreatedAt: row.CreatedAt}
+	}
+
+	return last, nil
+}
+
+// countUnread menghitung pesan belum dibaca per member, key member id
+func (r *chatRepo) countUnread(memberIds []uint) (map[uint]int64, error) {
+	var rows []struct {
+		MemberID uint
+		Total    int64
+	}
+	err := r.db.Model(&model.ChatRead{}).
+		Select("member_id, COUNT(*) AS total").
+		Where("member_id IN ? AND is_read = ?", memberIds, false).
+		Group("member_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	unread := make(map[uint]int64, len(rows))
+	for _, row := range rows {
+		unread[row.MemberID] = row.Total
+	}
+
+	return unread, nil
+}
+
+func toResponseChat(c *model.Chat) dto.ResponseChat {
+	response := dto.ResponseChat{
+		ID:        c.ID,
+		Type:      c.Type,
+		Message:   c.Message,
+		TopicId:   c.TopicID,
+		ThreadId:  c.ThreadRootID,
+		AlsoSent:  c.AlsoSent,
+		CreatedAt: c.CreatedAt,
+		EditedAt:  c.EditedAt,
+		Deleted:   c.DeletedAt != nil,
+		PinnedAt:  c.PinnedAt,
+		Status:    make([]dto.StatusChatRead, 0, len(c.ReadStatus)),
+	}
+	if c.GroupMemberID != nil {
+		response.MemberId = *c.GroupMemberID
+	}
+	if c.GroupMember != nil {
+		response.DisplayName = c.GroupMember.DisplayName()
+	}
+	if c.Type == model.ChatTypeSystem && c.Meta != "" {
+		var info dto.SystemInfo
+		if err := json.Unmarshal([]byte(c.Meta), &info); err == nil {
+			response.System = &info
+		}
+	}
+	for _, cr := range c.ReadStatus {
+		response.Status = append(response.Status, dto.StatusChatRead{
+			MemberId: cr.MemberId,
+			IsRead:   cr.IsRead,
 		})
 	}
 
+	return response
+}
+
+// toResponses mengubah chat menjadi payload lengkap dengan cuplikan balasan, ringkasan thread dan reaksi
+func (r *chatRepo) toResponses(chats []model.Chat, viewerId uint) ([]dto.ResponseChat, error) {
+	response := make([]dto.ResponseChat, 0, len(chats))
+	parentIds := make(map[int]uint)
+	for i := range chats {
+		response = append(response, toResponseChat(&chats[i]))
+		if chats[i].ParentID != nil {
+			parentIds[i] = *chats[i].ParentID
+		}
+	}
+	if err := r.attachQuotes(response, parentIds); err != nil {
+		return nil, err
+	}
+	if err := r.attachThreads(response, chats); err != nil {
+		return nil, err
+	}
+	if err := r.attachReactions(response, viewerId); err != nil {
+		return nil, err
+	}
+	if err := r.attachMentions(response, viewerId); err != nil {
+		return nil, err
+	}
+	if err := r.attachForwards(response, chats); err != nil {
+		return nil, err
+	}
+	if err := r.attachAttachments(response); err != nil {
+		return nil, err
+	}
+
 	return response, nil
 }
 
-func (r *chatRepo) CreateChat(memberId, groupId uint, message string, status []dto.MemberStatus) (*dto.ResponseChat, error) {
-	tx := r.db.Begin()
-	newChat := model.Chat{
-		GroupMemberID: &memberId,
-		GroupID:       groupId,
-		Message:       message,
+// GetChatPage mengambil pesan sebelum (older) atau sesudah cursor, urut dari yang terlama.
+// Tanpa cursor yang diambil adalah pesan terbaru.
+func (r *chatRepo) GetChatPage(filter *dto.ChatFilter, cursor *dto.ChatCursor, older bool, limit int) ([]dto.ResponseChat, error) {
+	query := r.db.Model(&model.Chat{}).Preload("ReadStatus").Preload("GroupMember.User").Where("group_id = ?", filter.GroupId)
+	if filter.ThreadId != nil {
+		query = query.Where("thread_root_id = ?", *filter.ThreadId)
+	} else {
+		query = whereTimeline(whereTopic(query, "topic_id", filter.TopicId))
 	}
-	if err := r.db.Create(&newChat).Error; err != nil {
+	query = whereVisible(query, "id", filter.ViewerId)
+
+	switch {
+	case cursor != nil && older:
+		query = query.Where("created_at < ? OR (created_at = ? AND id < ?)", cursor.CreatedAt, cursor.CreatedAt, cursor.ID)
+	case cursor != nil:
+		query = query.Where("created_at > ? OR (created_at = ? AND id > ?)", cursor.CreatedAt, cursor.CreatedAt, cursor.ID)
+	}
+	if older {
+		query = query.Order("created_at DESC, id DESC")
+	} else {
+		query = query.Order("created_at, id")
+	}
+
+	var chats []model.Chat
+	if err := query.Limit(limit).Find(&chats).Error; err != nil {
+		return nil, err
+	}
+	if older {
+		slices.Reverse(chats)
+	}
+
+	return r.toResponses(chats, filter.ViewerId)
+}
+
+func (r *chatRepo) CreateChat(newChat *model.Chat, status []dto.MemberStatus) (*dto.ResponseChat, error) {
+	memberId := *newChat.GroupMemberID
+	tx := r.db.Begin()
+	// lampiran sudah ada di tabelnya, cukup ditautkan setelah pesan dibuat
+	if err := tx.Omit("Attachments").Create(newChat).Error; err != nil {
 		tx.Rollback()
 		return nil, err
 	}
+	if len(newChat.Attachments) > 0 {
+		if err := linkAttachments(tx, newChat.ID, newChat.Attachments); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
 
 	membersStatus := make([]model.ChatRead, 0, len(status))
 	membersStatusResponse := make([]dto.StatusChatRead, 0, len(status))
@@ -182,68 +696,198 @@ func (r *chatRepo) CreateChat(memberId, groupId uint, message string, status []d
 		}
 	}
 
-	tx.Commit()
+	if newChat.ThreadRootID != nil {
+		err := tx.Model(&model.Chat{}).Where("id = ?", *newChat.ThreadRootID).Updates(map[string]interface{}{
+			"reply_count":   gorm.Expr("reply_count + 1"),
+			"last_reply_at": newChat.CreatedAt,
+		}).Error
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
 	response := dto.ResponseChat{
 		ID:        newChat.ID,
 		MemberId:  *newChat.GroupMemberID,
+		Type:      newChat.Type,
 		Message:   newChat.Message,
+		TopicId:   newChat.TopicID,
+		ThreadId:  newChat.ThreadRootID,
+		AlsoSent:  newChat.AlsoSent,
 		CreatedAt: newChat.CreatedAt,
 		Status:    membersStatusResponse,
 	}
+	if newChat.ParentID != nil {
+		chats := []dto.ResponseChat{response}
+		if err := r.attachQuotes(chats, map[int]uint{0: *newChat.ParentID}); err != nil {
+			return nil, err
+		}
+		response = chats[0]
+	}
+	if len(newChat.Mentions) > 0 {
+		chats := []dto.ResponseChat{response}
+		if err := r.attachMentions(chats, 0); err != nil {
+			return nil, err
+		}
+		response = chats[0]
+	}
+	if newChat.Forwarded {
+		chats := []dto.ResponseChat{response}
+		if err := r.attachForwards(chats, []model.Chat{*newChat}); err != nil {
+			return nil, err
+		}
+		response = chats[0]
+	}
+	for i := range newChat.Attachments {
+		response.Attachments = append(response.Attachments, toAttachmentResponse(&newChat.Attachments[i]))
+	}
 	return &response, nil
 }
 
-func (r *chatRepo) UpdateChat(chatId, memberId uint, message string) (*dto.ResponseChat, error) {
-	err := r.db.Model(&model.Chat{}).Where("id = ? AND member_id = ?", chatId, memberId).Updates(map[string]interface{}{
-		"message":    message,
-		"created_at": time.Now(),
-	}).Error
+func (r *chatRepo) CreateSystemChat(groupId uint, message string, info *dto.SystemInfo) (*dto.ResponseChat, error) {
+	meta, err := json.Marshal(info)
 	if err != nil {
 		return nil, err
 	}
+
+	newChat := model.Chat{
+		GroupID: groupId,
+		Type:    model.ChatTypeSystem,
+		Message: message,
+		Meta:    string(meta),
+	}
+	if err := r.db.Create(&newChat).Error; err != nil {
+		return nil, err
+	}
+
+	response := toResponseChat(&newChat)
+	return &response, nil
+}
+
+// GetLastChatTime mengembalikan nil jika member belum pernah mengirim pesan
+func (r *chatRepo) GetLastChatTime(memberId uint) (*time.Time, error) {
+	var chat model.Chat
+	err := r.db.Model(&model.Chat{}).Select("created_at").Where("group_member_id = ?", memberId).Order("created_at DESC").First(&chat).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, utils.ErrrnotChat
+		return nil, nil
 	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &chat.CreatedAt, nil
+}
 
-	var status []dto.StatusChatRead
-	if err := r.db.Model(&model.ChatRead{}).Select("member_id, is_read").Where("chat_id = ?", chatId).Scan(&status).Error; err != nil {
+func (r *chatRepo) GetUsernames(userIds []uint) (map[uint]string, error) {
+	var users []model.User
+	if err := r.db.Model(&model.User{}).Select("id", "username").Where("id IN ?", userIds).Find(&users).Error; err != nil {
 		return nil, err
 	}
-	response := dto.ResponseChat{
-		ID:        chatId,
-		MemberId:  memberId,
-		Message:   message,
-		CreatedAt: time.Now(),
-		Status:    status,
+
+	usernames := make(map[uint]string, len(users))
+	for _, u := range users {
+		usernames[u.ID] = u.Username
 	}
-	return &response, nil
+	return usernames, nil
 }
 
-func (r *chatRepo) DeleteChat(memberId, id uint) (*dto.ResponseChat, error) {
-	err := r.db.Model(&model.Chat{}).Where("id = ? AND member_id = ?", id, memberId).Delete(&model.Chat{}).Error
+// DeleteChat menjadikan pesan tombstone, revisi dan reaksinya ikut dihapus, lampirannya dilepas
+func (r *chatRepo) DeleteChat(chat *model.Chat, deletedBy uint) error {
+	tx := r.db.Begin()
+	now := time.Now()
+	result := tx.Model(&model.Chat{}).Where("id = ? AND deleted_at IS NULL", chat.ID).Updates(map[string]interface{}{
+		"message":    "",
+		"meta":       "",
+		"deleted_at": now,
+		"deleted_by": deletedBy,
+		"pinned_at":  nil,
+		"pinned_by":  nil,
+	})
+	if result.Error != nil {
+		tx.Rollback()
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return utils.ErrChatNotFound
+	}
+	if err := tx.Where("chat_id = ?", chat.ID).Delete(&model.ChatRevision{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Where("chat_id = ?", chat.ID).Delete(&model.Reaction{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Where("chat_id = ?", chat.ID).Delete(&model.Mention{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	// lampiran dilepas dari pesan supaya file-nya dibersihkan dan tidak bisa dipakai ulang pengunggahnya
+	err := tx.Model(&model.Attachment{}).Where("chat_id = ?", chat.ID).Updates(map[string]interface{}{
+		"chat_id":   nil,
+		"member_id": nil,
+	}).Error
 	if err != nil {
-		return nil, err
+		tx.Rollback()
+		return err
 	}
-	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, utils.ErrrnotChat
+	// pesan yang sudah dihapus tidak dihitung sebagai belum dibaca
+	if err := tx.Model(&model.ChatRead{}).Where("chat_id = ? AND is_read = ?", chat.ID, false).Update("is_read", true).Error; err != nil {
+		tx.Rollback()
+		return err
 	}
 
-	response := dto.ResponseChat{
-		ID:        id,
-		MemberId:  memberId,
-		Message:   "has been deleted",
-		CreatedAt: time.Now(),
+	// jumlah balasan di pesan induk ikut dikurangi
+	if chat.ThreadRootID != nil {
+		err := tx.Model(&model.Chat{}).Where("id = ? AND reply_count > 0", *chat.ThreadRootID).
+			Update("reply_count", gorm.Expr("reply_count - 1")).Error
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
 	}
-	return &response, nil
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+	chat.Message = ""
+	chat.Meta = ""
+	chat.DeletedAt = &now
+	chat.DeletedBy = &deletedBy
+	chat.PinnedAt = nil
+	chat.PinnedBy = nil
+	return nil
+}
+
+func (r *chatRepo) HideChat(chatId, memberId uint) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&model.HiddenChat{
+		ChatID:   chatId,
+		MemberID: memberId,
+	}).Error
+}
+
+// whereVisible membuang pesan yang disembunyikan viewer, viewer 0 berarti tanpa filter
+func whereVisible(db *gorm.DB, column string, viewerId uint) *gorm.DB {
+	if viewerId == 0 {
+		return db
+	}
+	return db.Where(column+" NOT IN (SELECT chat_id FROM hidden_chats WHERE member_id = ?)", viewerId)
 }
 
 func (r *chatRepo) GetMemberId(id, groupId uint) (uint, error) {
 	var member model.GroupMember
-	err := r.db.Model(&model.GroupMember{}).Select("id").Where("user_id = ? AND group_id = ?", id, groupId).First(&member).Error
-	if err != nil {
-		return 0, err
-	}
+	// grup yang sedang dihapus tidak bisa diakses lagi oleh member-nya
+	activeGroups := r.db.Model(&model.ChatGroup{}).Select("id")
+	err := r.db.Model(&model.GroupMember{}).Select("id").Where("user_id = ? AND group_id = ? AND group_id IN (?)", id, groupId, activeGroups).First(&member).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, utils.ErrNotMember
+	}
+	if err != nil {
 		return 0, err
 	}
 
@@ -256,8 +900,11 @@ func (r *chatRepo) GetGroupMembers(groupID uint) ([]uint, error) {
 	return members, err
 }
 
-func (r *chatRepo) UpdateStatusChat(memberId uint) error {
-	if err := r.db.Model(&model.ChatRead{}).Where("member_id = ?  AND is_read = ?", memberId, false).Update("is_read", true).Error; err != nil {
+// UpdateStatusChat hanya menandai pesan timeline, balasan thread ditandai lewat MarkThreadRead
+func (r *chatRepo) UpdateStatusChat(memberId uint, topicId *uint) error {
+	timeline := whereTimeline(whereTopic(r.db.Model(&model.Chat{}).Select("id"), "topic_id", topicId))
+	query := r.db.Model(&model.ChatRead{}).Where("member_id = ?  AND is_read = ? AND chat_id IN (?)", memberId, false, timeline)
+	if err := query.Update("is_read", true).Error; err != nil {
 		return err
 	}
 
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"api_chat_ws/dto"
+	"api_chat_ws/model"
+)
+
+// attachForwards mengisi asal pesan terusan beserta username penulis aslinya
+func (r *chatRepo) attachForwards(response []dto.ResponseChat, chats []model.Chat) error {
+	userIds := make([]uint, 0)
+	for i := range chats {
+		if !chats[i].Forwarded {
+			continue
+		}
+		info := dto.ForwardInfo{}
+		if chats[i].ForwardChatID != nil {
+			info.ChatId = *chats[i].ForwardChatID
+		}
+		if chats[i].ForwardGroupID != nil {
+			info.GroupId = *chats[i].ForwardGroupID
+		}
+		if chats[i].ForwardUserID != nil {
+			info.UserId = *chats[i].ForwardUserID
+			userIds = append(userIds, info.UserId)
+		}
+		response[i].ForwardedFrom = &info
+	}
+	if len(userIds) == 0 {
+		return nil
+	}
+
+	usernames, err := r.GetUsernames(userIds)
+	if err != nil {
+		return err
+	}
+	for i := range response {
+		if response[i].ForwardedFrom != nil && response[i].ForwardedFrom.UserId != 0 {
+			response[i].ForwardedFrom.Username = usernames[response[i].ForwardedFrom.UserId]
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"api_chat_ws/model"
+	"strings"
+)
+
+// FindUsersByIdentifiers mencocokkan email (tanpa membedakan huruf besar kecil) atau username
+func (r *chatRepo) FindUsersByIdentifiers(identifiers []string) ([]model.User, error) {
+	emails := make([]string, 0, len(identifiers))
+	for _, id := range identifiers {
+		emails = append(emails, strings.ToLower(id))
+	}
+
+	var users []model.User
+	err := r.db.Model(&model.User{}).Select("id, username, email").
+		Where("LOWER(email) IN ? OR username IN ?", emails, identifiers).
+		Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func (r *chatRepo) GetMemberUserIds(groupId uint, userIds []uint) ([]uint, error) {
+	var existing []uint
+	err := r.db.Model(&model.GroupMember{}).
+		Where("group_id = ? AND user_id IN ?", groupId, userIds).
+		Pluck("user_id", &existing).Error
+
+	return existing, err
+}
+
+// ImportMembers menyimpan semua member dalam satu transaksi, gagal satu berarti batal semua
+func (r *chatRepo) ImportMembers(members []model.GroupMember) error {
+	tx := r.db.Begin()
+	if err := tx.Model(&model.GroupMember{}).CreateInBatches(&members, 100).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
@@ -0,0 +1,40 @@
+package repository
+
+import "api_chat_ws/model"
+
+func (r *chatRepo) CountMembers(groupId uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.GroupMember{}).Where("group_id = ?", groupId).Count(&count).Error
+	return count, err
+}
+
+// CountGroupsCreated tidak menghitung grup yang sudah dihapus
+func (r *chatRepo) CountGroupsCreated(userId uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.ChatGroup{}).Where("created_by = ? AND type = ?", userId, model.GroupTypeGroup).Count(&count).Error
+	return count, err
+}
+
+// CountGroupsJoined hanya menghitung grup biasa, chat pribadi tidak masuk kuota
+func (r *chatRepo) CountGroupsJoined(userIds []uint) (map[uint]int64, error) {
+	var rows []struct {
+		UserID uint
+		Total  int64
+	}
+	err := r.db.Model(&model.GroupMember{}).
+		Select("group_members.user_id, COUNT(*) AS total").
+		Joins("JOIN chat_groups ON chat_groups.id = group_members.group_id AND chat_groups.deleted_at IS NULL").
+		Where("group_members.user_id IN ? AND chat_groups.type = ?", userIds, model.GroupTypeGroup).
+		Group("group_members.user_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	joined := make(map[uint]int64, len(rows))
+	for _, row := range rows {
+		joined[row.UserID] = row.Total
+	}
+
+	return joined, nil
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"api_chat_ws/dto"
+	"api_chat_ws/helper/utils"
+	"api_chat_ws/model"
+	"strings"
+)
+
+func (r *chatRepo) FindMembersByUsernames(groupId uint, usernames []string) ([]model.GroupMember, error) {
+	var members []model.GroupMember
+	err := r.db.Model(&model.GroupMember{}).Preload("User").
+		Joins("JOIN users ON users.id = group_members.user_id").
+		Where("group_members.group_id = ? AND users.username IN ?", groupId, usernames).
+		Find(&members).Error
+	return members, err
+}
+
+// countUnreadMentions menghitung mention yang pesannya belum dibaca per member, key member id
+func (r *chatRepo) countUnreadMentions(memberIds []uint) (map[uint]int64, error) {
+	var rows []struct {
+		MemberID uint
+		Total    int64
+	}
+	err := r.db.Model(&model.Mention{}).
+		Select("mentions.member_id, COUNT(*) AS total").
+		Joins("JOIN chat_reads ON chat_reads.chat_id = mentions.chat_id AND chat_reads.member_id = mentions.member_id").
+		Where("mentions.member_id IN ? AND chat_reads.is_read = ?", memberIds, false).
+		Group("mentions.member_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	unread := make(map[uint]int64, len(rows))
+	for _, row := range rows {
+		unread[row.MemberID] = row.Total
+	}
+
+	return unread, nil
+}
+
+// attachMentions mengisi posisi mention yang berhasil di-resolve saat pesan dikirim
+func (r *chatRepo) attachMentions(response []dto.ResponseChat, viewerId uint) error {
+	index := make(map[uint]int)
+	chatIds := make([]uint, 0)
+	for i := range response {
+		if response[i].Type == model.ChatTypeMessage && strings.Contains(response[i].Message, "@") {
+			index[response[i].ID] = i
+			chatIds = append(chatIds, response[i].ID)
+		}
+	}
+	if len(chatIds) == 0 {
+		return nil
+	}
+
+	// mention langsung beserta username-nya, ditambah baris milik viewer untuk mentioned_me
+	var users []struct {
+		ChatID   uint
+		MemberID uint
+		Kind     string
+		Username string
+	}
+	err := r.db.Model(&model.Mention{}).
+		Select("mentions.chat_id, mentions.member_id, mentions.kind, users.username").
+		Joins("JOIN group_members ON group_members.id = mentions.member_id").
+		Joins("JOIN users ON users.id = group_members.user_id").
+		Where("mentions.chat_id IN ? AND (mentions.kind = ? OR mentions.member_id = ?)", chatIds, model.MentionUser, viewerId).
+		Scan(&users).Error
+	if err != nil {
+		return err
+	}
+
+	var kinds []struct {
+		ChatID uint
+		Kind   string
+	}
+	err = r.db.Model(&model.Mention{}).
+		Select("DISTINCT chat_id, kind").
+		Where("chat_id IN ? AND kind <> ?", chatIds, model.MentionUser).
+		Scan(&kinds).Error
+	if err != nil {
+		return err
+	}
+
+	resolved := make(map[uint]map[string]uint, len(chatIds))
+	for _, id := range chatIds {
+		resolved[id] = make(map[string]uint)
+	}
+	for _, row := range users {
+		if row.MemberID == viewerId {
+			response[index[row.ChatID]].MentionedMe = true
+		}
+		if row.Kind == model.MentionUser {
+			resolved[row.ChatID][row.Username] = row.MemberID
+		}
+	}
+	for _, row := range kinds {
+		resolved[row.ChatID]["@"+row.Kind] = 0
+	}
+
+	for _, id := range chatIds {
+		chat := &response[index[id]]
+		for _, token := range utils.ParseMentions(chat.Message) {
+			span := dto.MentionSpan{Offset: token.Offset, Length: token.Length}
+			if token.Name == model.MentionHere || token.Name == model.MentionEveryone {
+				if _, ok := resolved[id]["@"+token.Name]; !ok {
+					continue
+				}
+				span.Kind = token.Name
+			} else {
+				memberId, ok := resolved[id][token.Name]
+				if !ok {
+					continue
+				}
+				span.Kind = model.MentionUser
+				span.MemberId = memberId
+			}
+			chat.Mentions = append(chat.Mentions, span)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"api_chat_ws/model"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func activeSanction(db *gorm.DB, now time.Time) *gorm.DB {
+	return db.Where("lifted_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", now)
+}
+
+// CreateSanction menggantikan sanksi aktif dengan tipe yang sama, member dihapus jika removeMemberId diisi
+func (r *chatRepo) CreateSanction(sanction *model.GroupSanction, removeMemberId uint) error {
+	tx := r.db.Begin()
+	now := time.Now()
+
+	err := activeSanction(tx.Model(&model.GroupSanction{}), now).
+		Where("group_id = ? AND user_id = ? AND type = ?", sanction.GroupID, sanction.UserID, sanction.Type).
+		Update("lifted_at", now).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Create(sanction).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if removeMemberId != 0 {
+		if err := tx.Model(&model.GroupMember{}).Where("id = ?", removeMemberId).Delete(&model.GroupMember{}).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit().Error
+}
+
+func (r *chatRepo) LiftSanction(groupId, userId uint, sanctionType string) (*model.GroupSanction, error) {
+	sanction, err := r.GetActiveSanction(groupId, userId, sanctionType)
+	if err != nil || sanction == nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := r.db.Model(&model.GroupSanction{}).Where("id = ?", sanction.ID).Update("lifted_at", now).Error; err != nil {
+		return nil, err
+	}
+	sanction.LiftedAt = &now
+
+	return sanction, nil
+}
+
+// GetActiveSanction mengembalikan nil jika user tidak sedang dikenai sanksi
+func (r *chatRepo) GetActiveSanction(groupId, userId uint, sanctionType string) (*model.GroupSanction, error) {
+	var sanction model.GroupSanction
+	err := activeSanction(r.db.Model(&model.GroupSanction{}), time.Now()).
+		Where("group_id = ? AND user_id = ? AND type = ?", groupId, userId, sanctionType).
+		Order("id DESC").
+		First(&sanction).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &sanction, nil
+}
+
+func (r *chatRepo) GetActiveSanctions(groupId uint) ([]model.GroupSanction, error) {
+	var sanctions []model.GroupSanction
+	err := activeSanction(r.db.Model(&model.GroupSanction{}), time.Now()).
+		Where("group_id = ?", groupId).
+		Order("created_at DESC").
+		Find(&sanctions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return sanctions, nil
+}
+
+func (r *chatRepo) GetBannedUsers(groupId uint, userIds []uint) ([]uint, error) {
+	var banned []uint
+	err := activeSanction(r.db.Model(&model.GroupSanction{}), time.Now()).
+		Where("group_id = ? AND type = ? AND user_id IN ?", groupId, model.SanctionBan, userIds).
+		Distinct().
+		Pluck("user_id", &banned).Error
+
+	return banned, err
+}
+
+// ExpireSanctions menandai sanksi yang sudah lewat masa berlakunya dan mengembalikannya untuk dinotifikasi
+func (r *chatRepo) ExpireSanctions(now time.Time) ([]model.GroupSanction, error) {
+	var expired []model.GroupSanction
+	err := r.db.Model(&model.GroupSanction{}).
+		Where("lifted_at IS NULL AND expires_at IS NOT NULL AND expires_at <= ?", now).
+		Find(&expired).Error
+	if err != nil || len(expired) == 0 {
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(expired))
+	for i := range expired {
+		ids = append(ids, expired[i].ID)
+		expired[i].LiftedAt = &now
+	}
+	if err := r.db.Model(&model.GroupSanction{}).Where("id IN ?", ids).Update("lifted_at", now).Error; err != nil {
+		return nil, err
+	}
+
+	return expired, nil
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"api_chat_ws/model"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetPermission mengembalikan nil jika grup belum mengatur permission tersebut
+func (r *chatRepo) GetPermission(groupId uint, role, perm string) (*bool, error) {
+	var permission model.GroupPermission
+	err := r.db.Model(&model.GroupPermission{}).Where("group_id = ? AND role = ? AND permission = ?", groupId, role, perm).First(&permission).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &permission.Allowed, nil
+}
+
+func (r *chatRepo) GetPermissions(groupId uint) ([]model.GroupPermission, error) {
+	var permissions []model.GroupPermission
+	if err := r.db.Model(&model.GroupPermission{}).Where("group_id = ?", groupId).Find(&permissions).Error; err != nil {
+		return nil, err
+	}
+
+	return permissions, nil
+}
+
+func (r *chatRepo) SetPermissions(groupId uint, role string, permissions map[string]bool) error {
+	rows := make([]model.GroupPermission, 0, len(permissions))
+	for perm, allowed := range permissions {
+		rows = append(rows, model.GroupPermission{
+			GroupID:    groupId,
+			Role:       role,
+			Permission: perm,
+			Allowed:    allowed,
+		})
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	return r.db.Model(&model.GroupPermission{}).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "group_id"}, {Name: "role"}, {Name: "permission"}},
+		DoUpdates: clause.AssignmentColumns([]string{"allowed"}),
+	}).Create(&rows).Error
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"api_chat_ws/dto"
+	"api_chat_ws/helper/utils"
+	"api_chat_ws/model"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// PinChat menyematkan pesan dalam satu transaksi. Baris grup dikunci supaya dua request bersamaan
+// tidak sama-sama lolos cek batas, limit 0 berarti tanpa batas.
+func (r *chatRepo) PinChat(groupId, chatId, memberId uint, limit int) (*time.Time, error) {
+	tx := r.db.Begin()
+	var group model.ChatGroup
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", groupId).First(&group).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if limit > 0 {
+		var count int64
+		if err := tx.Model(&model.Chat{}).Where("group_id = ? AND pinned_at IS NOT NULL", groupId).Count(&count).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if count >= int64(limit) {
+			tx.Rollback()
+			return nil, utils.ErrPinLimit
+		}
+	}
+
+	now := time.Now()
+	result := tx.Model(&model.Chat{}).Where("id = ? AND deleted_at IS NULL AND pinned_at IS NULL", chatId).Updates(map[string]interface{}{
+		"pinned_at": now,
+		"pinned_by": memberId,
+	})
+	if result.Error != nil {
+		tx.Rollback()
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		// bisa karena sudah disematkan atau karena pesannya dihapus setelah dibaca usecase
+		var alive int64
+		err := tx.Model(&model.Chat{}).Where("id = ? AND deleted_at IS NULL", chatId).Count(&alive).Error
+		tx.Rollback()
+		if err != nil {
+			return nil, err
+		}
+		if alive == 0 {
+			return nil, utils.ErrChatNotFound
+		}
+		return nil, utils.ErrAlreadyPinned
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return &now, nil
+}
+
+func (r *chatRepo) UnpinChat(chatId uint) error {
+	result := r.db.Model(&model.Chat{}).Where("id = ? AND pinned_at IS NOT NULL", chatId).Updates(map[string]interface{}{
+		"pinned_at": nil,
+		"pinned_by": nil,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return utils.ErrNotPinned
+	}
+	return nil
+}
+
+// GetPinnedChats mengambil pesan yang disematkan, yang terakhir disematkan lebih dulu
+func (r *chatRepo) GetPinnedChats(groupId, viewerId uint) ([]dto.ResponseChat, error) {
+	query := r.db.Model(&model.Chat{}).Preload("ReadStatus").Preload("GroupMember.User").
+		Where("group_id = ? AND pinned_at IS NOT NULL", groupId)
+
+	var chats []model.Chat
+	if err := whereVisible(query, "id", viewerId).Order("pinned_at DESC, id DESC").Find(&chats).Error; err != nil {
+		return nil, err
+	}
+
+	return r.toResponses(chats, viewerId)
+}
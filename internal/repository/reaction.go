@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"api_chat_ws/dto"
+	"api_chat_ws/model"
+
+	"gorm.io/gorm/clause"
+)
+
+// AddReaction tidak error jika member sudah memberi emoji yang sama
+func (r *chatRepo) AddReaction(reaction *model.Reaction) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(reaction).Error
+}
+
+func (r *chatRepo) RemoveReaction(chatId, memberId uint, emoji string) (bool, error) {
+	result := r.db.Where("chat_id = ? AND member_id = ? AND emoji = ?", chatId, memberId, emoji).Delete(&model.Reaction{})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *chatRepo) CountReaction(chatId uint, emoji string) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Reaction{}).Where("chat_id = ? AND emoji = ?", chatId, emoji).Count(&count).Error
+	return count, err
+}
+
+func (r *chatRepo) CountReactionKinds(chatId uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Reaction{}).Where("chat_id = ?", chatId).Distinct("emoji").Count(&count).Error
+	return count, err
+}
+
+// attachReactions mengisi jumlah reaksi per emoji, urut dari emoji yang pertama kali diberikan
+func (r *chatRepo) attachReactions(response []dto.ResponseChat, viewerId uint) error {
+	if len(response) == 0 {
+		return nil
+	}
+
+	index := make(map[uint]int, len(response))
+	chatIds := make([]uint, 0, len(response))
+	for i := range response {
+		index[response[i].ID] = i
+		chatIds = append(chatIds, response[i].ID)
+	}
+
+	var rows []struct {
+		ChatID uint
+		Emoji  string
+		Total  int64
+		Mine   int64
+	}
+	err := r.db.Model(&model.Reaction{}).
+		Select("chat_id, emoji, COUNT(*) AS total, SUM(CASE WHEN member_id = ? THEN 1 ELSE 0 END) AS mine, MIN(id) AS first_id", viewerId).
+		Where("chat_id IN ?", chatIds).
+		Group("chat_id, emoji").
+		Order("first_id").
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		i := index[row.ChatID]
+		response[i].Reactions = append(response[i].Reactions, dto.ReactionCount{
+			Emoji:       row.Emoji,
+			Count:       row.Total,
+			ReactedByMe: row.Mine > 0,
+		})
+	}
+
+	return nil
+}
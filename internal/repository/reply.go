@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"api_chat_ws/dto"
+	"api_chat_ws/helper/utils"
+	"api_chat_ws/model"
+	"errors"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+const quoteExcerptLength = 100
+
+func (r *chatRepo) GetChat(chatId uint) (*model.Chat, error) {
+	var chat model.Chat
+	err := r.db.Model(&model.Chat{}).Where("id = ?", chatId).First(&chat).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, utils.ErrChatNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &chat, nil
+}
+
+func toQuotedChat(c *model.Chat) *dto.QuotedChat {
+	quote := dto.QuotedChat{
+		ChatId:  c.ID,
+		Excerpt: c.Message,
+		Deleted: c.DeletedAt != nil,
+	}
+	if utf8.RuneCountInString(quote.Excerpt) > quoteExcerptLength {
+		quote.Excerpt = string([]rune(quote.Excerpt)[:quoteExcerptLength]) + "…"
+	}
+	if c.GroupMemberID != nil {
+		quote.MemberId = *c.GroupMemberID
+	}
+	if c.GroupMember != nil {
+		quote.DisplayName = c.GroupMember.DisplayName()
+	}
+
+	return &quote
+}
+
+// attachQuotes mengisi ReplyTo untuk semua balasan dengan satu query,
+// pesan induk yang sudah tidak ada ditandai Deleted
+func (r *chatRepo) attachQuotes(chats []dto.ResponseChat, parentIds map[int]uint) error {
+	if len(parentIds) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, 0, len(parentIds))
+	for _, id := range parentIds {
+		ids = append(ids, id)
+	}
+
+	var parents []model.Chat
+	if err := r.db.Model(&model.Chat{}).Preload("GroupMember.User").Where("id IN ?", ids).Find(&parents).Error; err != nil {
+		return err
+	}
+	found := make(map[uint]*model.Chat, len(parents))
+	for i := range parents {
+		found[parents[i].ID] = &parents[i]
+	}
+
+	for i, id := range parentIds {
+		if parent, ok := found[id]; ok {
+			chats[i].ReplyTo = toQuotedChat(parent)
+		} else {
+			chats[i].ReplyTo = &dto.QuotedChat{ChatId: id, Deleted: true}
+		}
+	}
+
+	return nil
+}
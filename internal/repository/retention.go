@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"api_chat_ws/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func (r *chatRepo) UpdateGroupSettings(groupId uint, updated map[string]interface{}) error {
+	return r.db.Model(&model.ChatGroup{}).Where("id = ?", groupId).Updates(updated).Error
+}
+
+// GetRetentionGroups mengambil grup yang pesannya perlu dibersihkan
+func (r *chatRepo) GetRetentionGroups(defaultDays int) ([]model.ChatGroup, error) {
+	query := r.db.Model(&model.ChatGroup{}).Select("id", "retention_days", "legal_hold").Where("legal_hold = ?", false)
+	if defaultDays > 0 {
+		query = query.Where("retention_days IS NULL OR retention_days > 0")
+	} else {
+		query = query.Where("retention_days > 0")
+	}
+
+	var groups []model.ChatGroup
+	if err := query.Find(&groups).Error; err != nil {
+		return nil, err
+	}
+
+	return groups, nil
+}
+
+// PurgeChatBatch menghapus paling banyak limit chat berdasarkan primary key,
+// transaksinya dibuat pendek supaya tabel chats tidak terkunci lama
+func (r *chatRepo) PurgeChatBatch(groupId uint, before time.Time, limit int) (int, error) {
+	var ids []uint
+	err := r.db.Model(&model.Chat{}).
+		Where("group_id = ? AND created_at < ?", groupId, before).
+		Order("id").
+		Limit(limit).
+		Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+
+	tx := r.db.Begin()
+	var roots []uint
+	if err := tx.Model(&model.Chat{}).Where("id IN ? AND thread_root_id IS NOT NULL", ids).Distinct().Pluck("thread_root_id", &roots).Error; err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	if err := tx.Model(&model.ChatRead{}).Where("chat_id IN ?", ids).Delete(&model.ChatRead{}).Error; err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	if err := tx.Model(&model.Chat{}).Where("id IN ?", ids).Delete(&model.Chat{}).Error; err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	if err := refreshThreadSummary(tx, roots); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return 0, err
+	}
+
+	return len(ids), nil
+}
+
+// refreshThreadSummary menghitung ulang jumlah dan waktu balasan terakhir di pesan induk dari balasan yang tersisa,
+// balasan yang sudah dihapus tidak dihitung sama seperti DeleteChat
+func refreshThreadSummary(tx *gorm.DB, roots []uint) error {
+	if len(roots) == 0 {
+		return nil
+	}
+
+	var rows []struct {
+		ThreadRootID uint
+		Total        int64
+		LastReplyAt  *time.Time
+	}
+	err := tx.Model(&model.Chat{}).
+		Select("thread_root_id, SUM(CASE WHEN deleted_at IS NULL THEN 1 ELSE 0 END) AS total, MAX(created_at) AS last_reply_at").
+		Where("thread_root_id IN ?", roots).
+		Group("thread_root_id").
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	summaries := make(map[uint]int, len(rows))
+	for i, row := range rows {
+		summaries[row.ThreadRootID] = i
+	}
+	for _, root := range roots {
+		updated := map[string]interface{}{
+			"reply_count":   0,
+			"last_reply_at": nil,
+		}
+		if i, ok := summaries[root]; ok {
+			updated["reply_count"] = rows[i].Total
+			updated["last_reply_at"] = rows[i].LastReplyAt
+		}
+		if err := tx.Model(&model.Chat{}).Where("id = ?", root).Updates(updated).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
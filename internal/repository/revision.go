@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"api_chat_ws/helper/utils"
+	"api_chat_ws/model"
+	"time"
+)
+
+// UpdateChat menyimpan isi lama ke revisi lalu mengganti isi pesan, created_at tidak diubah
+func (r *chatRepo) UpdateChat(chat *model.Chat, message string) error {
+	tx := r.db.Begin()
+	revision := model.ChatRevision{
+		ChatID:  chat.ID,
+		Message: chat.Message,
+	}
+	if err := tx.Create(&revision).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// pesan yang dihapus setelah dibaca usecase tidak boleh terisi lagi
+	now := time.Now()
+	result := tx.Model(&model.Chat{}).Where("id = ? AND deleted_at IS NULL", chat.ID).Updates(map[string]interface{}{
+		"message":   message,
+		"edited_at": now,
+	})
+	if result.Error != nil {
+		tx.Rollback()
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return utils.ErrChatNotFound
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+	chat.Message = message
+	chat.EditedAt = &now
+	return nil
+}
+
+func (r *chatRepo) GetRevisions(chatId uint) ([]model.ChatRevision, error) {
+	var revisions []model.ChatRevision
+	err := r.db.Model(&model.ChatRevision{}).Where("chat_id = ?", chatId).Order("created_at, id").Find(&revisions).Error
+	return revisions, err
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"api_chat_ws/dto"
+	"api_chat_ws/helper/utils"
+	"api_chat_ws/model"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// attachThreads mengisi ringkasan thread untuk pesan induk yang sudah punya balasan
+func (r *chatRepo) attachThreads(response []dto.ResponseChat, chats []model.Chat) error {
+	rootIds := make([]uint, 0)
+	for i := range chats {
+		if chats[i].ReplyCount > 0 {
+			rootIds = append(rootIds, chats[i].ID)
+		}
+	}
+	if len(rootIds) == 0 {
+		return nil
+	}
+
+	var rows []struct {
+		ThreadRootID  uint
+		GroupMemberID uint
+	}
+	err := r.db.Model(&model.Chat{}).
+		Select("DISTINCT thread_root_id, group_member_id").
+		Where("thread_root_id IN ? AND group_member_id IS NOT NULL", rootIds).
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+	participants := make(map[uint][]uint, len(rootIds))
+	for _, row := range rows {
+		participants[row.ThreadRootID] = append(participants[row.ThreadRootID], row.GroupMemberID)
+	}
+
+	for i := range chats {
+		if chats[i].ReplyCount == 0 {
+			continue
+		}
+		members := participants[chats[i].ID]
+		if members == nil {
+			members = []uint{}
+		}
+		response[i].Thread = &dto.ThreadInfo{
+			ReplyCount:   chats[i].ReplyCount,
+			LastReplyAt:  chats[i].LastReplyAt,
+			Participants: members,
+		}
+	}
+
+	return nil
+}
+
+func (r *chatRepo) GetChatResponse(chatId, viewerId uint) (*dto.ResponseChat, error) {
+	var chat model.Chat
+	err := r.db.Model(&model.Chat{}).Preload("ReadStatus").Preload("GroupMember.User").Where("id = ?", chatId).First(&chat).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, utils.ErrChatNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := r.toResponses([]model.Chat{chat}, viewerId)
+	if err != nil {
+		return nil, err
+	}
+	return &response[0], nil
+}
+
+// GetThreadRoots mengambil pesan induk yang punya balasan, thread yang paling baru aktif lebih dulu
+func (r *chatRepo) GetThreadRoots(groupId, viewerId uint, limit int) ([]dto.ResponseChat, error) {
+	query := r.db.Model(&model.Chat{}).Preload("ReadStatus").Preload("GroupMember.User").
+		Where("group_id = ? AND reply_count > 0", groupId)
+
+	var chats []model.Chat
+	err := whereVisible(query, "id", viewerId).
+		Order("last_reply_at DESC, id DESC").
+		Limit(limit).
+		Find(&chats).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return r.toResponses(chats, viewerId)
+}
+
+func (r *chatRepo) GetThreadUnread(memberId uint, rootIds []uint) (map[uint]int64, error) {
+	var rows []struct {
+		ThreadRootID uint
+		Total        int64
+	}
+	err := r.db.Model(&model.ChatRead{}).
+		Select("chats.thread_root_id, COUNT(*) AS total").
+		Joins("JOIN chats ON chats.id = chat_reads.chat_id").
+		Where("chat_reads.member_id = ? AND chat_reads.is_read = ? AND chats.thread_root_id IN ?", memberId, false, rootIds).
+		Group("chats.thread_root_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	unread := make(map[uint]int64, len(rows))
+	for _, row := range rows {
+		unread[row.ThreadRootID] = row.Total
+	}
+
+	return unread, nil
+}
+
+func (r *chatRepo) MarkThreadRead(memberId, rootId uint) error {
+	replies := r.db.Model(&model.Chat{}).Select("id").Where("thread_root_id = ?", rootId)
+	return r.db.Model(&model.ChatRead{}).
+		Where("member_id = ? AND is_read = ? AND chat_id IN (?)", memberId, false, replies).
+		Update("is_read", true).Error
+}
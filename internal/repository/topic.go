@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"api_chat_ws/helper/utils"
+	"api_chat_ws/model"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+func (r *chatRepo) GetTopic(topicId uint) (*model.Topic, error) {
+	var topic model.Topic
+	err := r.db.Model(&model.Topic{}).Where("id = ?", topicId).First(&topic).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, utils.ErrTopicNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &topic, nil
+}
+
+func (r *chatRepo) GetTopics(groupId uint) ([]model.Topic, error) {
+	var topics []model.Topic
+	if err := r.db.Model(&model.Topic{}).Where("group_id = ?", groupId).Order("created_at, id").Find(&topics).Error; err != nil {
+		return nil, err
+	}
+
+	return topics, nil
+}
+
+func (r *chatRepo) topicNameTaken(groupId uint, name string, exceptId uint) (bool, error) {
+	var count int64
+	if err := r.db.Model(&model.Topic{}).Where("group_id = ? AND name = ? AND id <> ?", groupId, name, exceptId).Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (r *chatRepo) CreateTopic(topic *model.Topic) error {
+	taken, err := r.topicNameTaken(topic.GroupID, topic.Name, 0)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return utils.ErrTopicExists
+	}
+
+	return r.db.Create(topic).Error
+}
+
+func (r *chatRepo) RenameTopic(topicId uint, name string) error {
+	topic, err := r.GetTopic(topicId)
+	if err != nil {
+		return err
+	}
+
+	taken, err := r.topicNameTaken(topic.GroupID, name, topicId)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return utils.ErrTopicExists
+	}
+
+	return r.db.Model(&model.Topic{}).Where("id = ?", topicId).Update("name", name).Error
+}
+
+// GetTopicUnread menghitung pesan belum dibaca per topic, key 0 untuk topic umum
+func (r *chatRepo) GetTopicUnread(memberId uint) (map[uint]int64, error) {
+	var rows []struct {
+		TopicID uint
+		Total   int64
+	}
+	err := r.db.Model(&model.ChatRead{}).
+		Select("COALESCE(chats.topic_id, 0) AS topic_id, COUNT(*) AS total").
+		Joins("JOIN chats ON chats.id = chat_reads.chat_id AND (chats.thread_root_id IS NULL OR chats.also_sent = ?)", true).
+		Where("chat_reads.member_id = ? AND chat_reads.is_read = ?", memberId, false).
+		Group("COALESCE(chats.topic_id, 0)").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	unread := make(map[uint]int64, len(rows))
+	for _, row := range rows {
+		unread[row.TopicID] = row.Total
+	}
+
+	return unread, nil
+}
+
+// whereTimeline membuang balasan thread yang tidak ikut dikirim ke timeline utama
+func whereTimeline(db *gorm.DB) *gorm.DB {
+	return db.Where("thread_root_id IS NULL OR also_sent = ?", true)
+}
+
+// whereTopic memfilter chat per topic, nil berarti semua topic dan 0 berarti topic umum
+func whereTopic(db *gorm.DB, column string, topicId *uint) *gorm.DB {
+	if topicId == nil {
+		return db
+	}
+	if *topicId == 0 {
+		return db.Where(column + " IS NULL")
+	}
+	return db.Where(column+" = ?", *topicId)
+}
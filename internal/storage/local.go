@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// MediaPrefix adalah path tempat file driver local disajikan
+const MediaPrefix = "/media/"
+
+type Local struct {
+	root      string
+	publicURL string
+	signer    urlSigner
+}
+
+func NewLocal(root, publicURL, secret string) *Local {
+	return &Local{
+		root:      root,
+		publicURL: strings.TrimRight(publicURL, "/"),
+		signer:    urlSigner{secret: []byte(secret)},
+	}
+}
+
+func (l *Local) path(key string) (string, error) {
+	cleaned, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(l.root, filepath.FromSlash(cleaned)), nil
+}
+
+// Put menulis ke file sementara dulu supaya pembaca tidak pernah melihat file setengah jadi
+func (l *Local) Put(key string, data []byte, contentType string) error {
+	target, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), target)
+}
+
+func (l *Local) Get(key string) ([]byte, error) {
+	target, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(target)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
+
+func (l *Local) Delete(key string) error {
+	target, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(target); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (l *Local) URL(key string) string {
+	return l.publicURL + "/" + key
+}
+
+func (l *Local) SignedURL(key string, expires time.Duration) (string, error) {
+	if _, err := cleanKey(key); err != nil {
+		return "", err
+	}
+	return l.URL(key) + "?" + l.signer.sign(key, expires), nil
+}
+
+// Handler menyajikan file di bawah MediaPrefix, listing folder dimatikan.
+// File private hanya disajikan lewat URL bertanda tangan yang belum kedaluwarsa.
+func (l *Local) Handler() http.Handler {
+	files := http.FileServer(http.Dir(l.root))
+	return http.StripPrefix(strings.TrimRight(MediaPrefix, "/"), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/") {
+			http.NotFound(w, r)
+			return
+		}
+		if key := strings.TrimPrefix(path.Clean(r.URL.Path), "/"); strings.HasPrefix(key, PrivatePrefix) {
+			if !l.signer.verify(key, r.URL.Query()) {
+				http.NotFound(w, r)
+				return
+			}
+			// file kiriman user tidak boleh menjalankan script di origin ini
+			w.Header().Set("Content-Security-Policy", "default-src 'none'; sandbox")
+			w.Header().Set("X-Content-Type-Options", "nosniff")
+		}
+		files.ServeHTTP(w, r)
+	}))
+}
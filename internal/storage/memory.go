@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"bytes"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Memory menyimpan file di memori, dipakai untuk pengujian dan development tanpa disk atau S3
+type memoryFile struct {
+	data        []byte
+	contentType string
+}
+
+type Memory struct {
+	mu        sync.RWMutex
+	files     map[string]memoryFile
+	publicURL string
+	signer    urlSigner
+}
+
+func NewMemory(publicURL, secret string) *Memory {
+	return &Memory{
+		files:     make(map[string]memoryFile),
+		publicURL: strings.TrimRight(publicURL, "/"),
+		signer:    urlSigner{secret: []byte(secret)},
+	}
+}
+
+func (m *Memory) Put(key string, data []byte, contentType string) error {
+	cleaned, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.files[cleaned] = memoryFile{data: append([]byte(nil), data...), contentType: contentType}
+	return nil
+}
+
+func (m *Memory) Get(key string) ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	file, ok := m.files[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return append([]byte(nil), file.data...), nil
+}
+
+func (m *Memory) Delete(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.files, key)
+	return nil
+}
+
+func (m *Memory) URL(key string) string {
+	return m.publicURL + "/" + key
+}
+
+func (m *Memory) SignedURL(key string, expires time.Duration) (string, error) {
+	if _, err := cleanKey(key); err != nil {
+		return "", err
+	}
+	return m.URL(key) + "?" + m.signer.sign(key, expires), nil
+}
+
+// Handler menyajikan file di bawah MediaPrefix dengan aturan yang sama seperti driver local
+func (m *Memory) Handler() http.Handler {
+	return http.StripPrefix(strings.TrimRight(MediaPrefix, "/"), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimPrefix(path.Clean(r.URL.Path), "/")
+		if strings.HasPrefix(key, PrivatePrefix) {
+			if !m.signer.verify(key, r.URL.Query()) {
+				http.NotFound(w, r)
+				return
+			}
+			w.Header().Set("Content-Security-Policy", "default-src 'none'; sandbox")
+			w.Header().Set("X-Content-Type-Options", "nosniff")
+		}
+
+		m.mu.RLock()
+		file, ok := m.files[key]
+		m.mu.RUnlock()
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if file.contentType != "" {
+			w.Header().Set("Content-Type", file.contentType)
+		}
+		http.ServeContent(w, r, path.Base(key), time.Time{}, bytes.NewReader(file.data))
+	}))
+}
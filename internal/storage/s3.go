@@ -0,0 +1,290 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	s3Service       = "s3"
+	s3Algorithm     = "AWS4-HMAC-SHA256"
+	s3UnsignedBody  = "UNSIGNED-PAYLOAD"
+	s3MaxPresignTTL = 7 * 24 * time.Hour
+)
+
+type S3Config struct {
+	Endpoint  string // contoh https://s3.amazonaws.com atau http://localhost:9000 untuk MinIO
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	PublicURL string // kosong berarti endpoint/bucket
+}
+
+// S3 menyimpan file di storage yang kompatibel dengan S3 (AWS, MinIO, R2, dll) dengan path-style URL.
+// Request ditandatangani dengan AWS Signature Version 4.
+type S3 struct {
+	endpoint  *url.URL
+	region    string
+	bucket    string
+	accessKey string
+	secretKey string
+	publicURL string
+	client    *http.Client
+	now       func() time.Time
+}
+
+func NewS3(cfg S3Config) (*S3, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" || cfg.AccessKey == "" || cfg.SecretKey == "" {
+		return nil, errors.New("storage: S3_ENDPOINT, S3_BUCKET, S3_ACCESS_KEY dan S3_SECRET_KEY wajib diisi")
+	}
+	endpoint, err := url.Parse(strings.TrimRight(cfg.Endpoint, "/"))
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("storage: S3_ENDPOINT %q tidak valid", cfg.Endpoint)
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	if cfg.PublicURL == "" {
+		cfg.PublicURL = endpoint.String() + "/" + cfg.Bucket
+	}
+
+	return &S3{
+		endpoint:  endpoint,
+		region:    cfg.Region,
+		bucket:    cfg.Bucket,
+		accessKey: cfg.AccessKey,
+		secretKey: cfg.SecretKey,
+		publicURL: strings.TrimRight(cfg.PublicURL, "/"),
+		client:    &http.Client{Timeout: 30 * time.Second},
+		now:       time.Now,
+	}, nil
+}
+
+func (s *S3) objectURL(key string) *url.URL {
+	u := *s.endpoint
+	u.Path = "/" + s.bucket + "/" + key
+	u.RawPath = "/" + uriEncode(s.bucket, true) + "/" + uriEncode(key, false)
+	return &u
+}
+
+func (s *S3) Put(key string, data []byte, contentType string) error {
+	if _, err := cleanKey(key); err != nil {
+		return err
+	}
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	res, err := s.do(http.MethodPut, key, data, header)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return s3Error(res)
+	}
+	return nil
+}
+
+func (s *S3) Get(key string) ([]byte, error) {
+	res, err := s.do(http.MethodGet, key, nil, http.Header{})
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, s3Error(res)
+	}
+	return io.ReadAll(res.Body)
+}
+
+// Delete tidak error jika file sudah tidak ada
+func (s *S3) Delete(key string) error {
+	res, err := s.do(http.MethodDelete, key, nil, http.Header{})
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusNoContent && res.StatusCode != http.StatusOK && res.StatusCode != http.StatusNotFound {
+		return s3Error(res)
+	}
+	return nil
+}
+
+func (s *S3) URL(key string) string {
+	return s.publicURL + "/" + key
+}
+
+// SignedURL membuat presigned URL GET, maksimal berlaku 7 hari sesuai batas S3
+func (s *S3) SignedURL(key string, expires time.Duration) (string, error) {
+	if _, err := cleanKey(key); err != nil {
+		return "", err
+	}
+	if expires > s3MaxPresignTTL {
+		expires = s3MaxPresignTTL
+	}
+
+	now := s.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	scope := s.scope(now)
+	u := s.objectURL(key)
+
+	query := url.Values{}
+	query.Set("X-Amz-Algorithm", s3Algorithm)
+	query.Set("X-Amz-Credential", s.accessKey+"/"+scope)
+	query.Set("X-Amz-Date", amzDate)
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expires.Seconds())))
+	query.Set("X-Amz-SignedHeaders", "host")
+
+	canonical := strings.Join([]string{
+		http.MethodGet,
+		u.EscapedPath(),
+		canonicalQuery(query),
+		"host:" + u.Host + "\n",
+		"host",
+		s3UnsignedBody,
+	}, "\n")
+	query.Set("X-Amz-Signature", s.signature(now, amzDate, scope, canonical))
+
+	u.RawQuery = canonicalQuery(query)
+	return u.String(), nil
+}
+
+// do mengirim request ke object key dengan header Authorization SigV4
+func (s *S3) do(method, key string, body []byte, header http.Header) (*http.Response, error) {
+	u := s.objectURL(key)
+	req, err := http.NewRequest(method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	now := s.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	payloadHash := sha256Hex(body)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders, canonicalHeaders := canonicalHeaders(req.Header, u.Host)
+	canonical := strings.Join([]string{
+		method,
+		u.EscapedPath(),
+		canonicalQuery(u.Query()),
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := s.scope(now)
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, s.accessKey, scope, signedHeaders, s.signature(now, amzDate, scope, canonical)))
+
+	return s.client.Do(req)
+}
+
+func (s *S3) scope(now time.Time) string {
+	return now.Format("20060102") + "/" + s.region + "/" + s3Service + "/aws4_request"
+}
+
+func (s *S3) signature(now time.Time, amzDate, scope, canonicalRequest string) string {
+	stringToSign := strings.Join([]string{s3Algorithm, amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.secretKey), now.Format("20060102"))
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, s3Service)
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+// canonicalHeaders mengembalikan daftar header yang ditandatangani dan bentuk kanoniknya.
+// Host selalu ikut, header lain hanya Content-* dan X-Amz-*.
+func canonicalHeaders(header http.Header, host string) (string, string) {
+	values := map[string]string{"host": host}
+	for k, v := range header {
+		name := strings.ToLower(k)
+		if name == "content-type" || name == "content-md5" || strings.HasPrefix(name, "x-amz-") {
+			values[name] = strings.TrimSpace(strings.Join(v, ","))
+		}
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonical strings.Builder
+	for _, name := range names {
+		canonical.WriteString(name + ":" + values[name] + "\n")
+	}
+	return strings.Join(names, ";"), canonical.String()
+}
+
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		values := append([]string(nil), query[k]...)
+		sort.Strings(values)
+		for _, v := range values {
+			pairs = append(pairs, uriEncode(k, true)+"="+uriEncode(v, true))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// uriEncode mengikuti aturan encoding SigV4: hanya karakter unreserved RFC 3986 yang tidak di-encode
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func s3Error(res *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+	return fmt.Errorf("storage: s3 %s : %s", res.Status, strings.TrimSpace(string(body)))
+}
@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// urlSigner membuat dan memeriksa tanda tangan HMAC untuk driver yang menyajikan file sendiri
+type urlSigner struct {
+	secret []byte
+}
+
+func (s urlSigner) signature(key string, expires int64) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(key + "\n" + strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// sign mengembalikan query expires dan signature untuk key
+func (s urlSigner) sign(key string, ttl time.Duration) string {
+	expires := time.Now().Add(ttl).Unix()
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("signature", s.signature(key, expires))
+	return query.Encode()
+}
+
+func (s urlSigner) verify(key string, query url.Values) bool {
+	if len(s.secret) == 0 {
+		return false
+	}
+	expires, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+	if err != nil || time.Now().Unix() > expires {
+		return false
+	}
+	return hmac.Equal([]byte(query.Get("signature")), []byte(s.signature(key, expires)))
+}
@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestURLSigner(t *testing.T) {
+	signer := urlSigner{secret: []byte(testSecret)}
+	key := "private/attachments/1/abc"
+	valid, _ := url.ParseQuery(signer.sign(key, time.Minute))
+
+	future := strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+	past := time.Now().Add(-time.Minute).Unix()
+	tampered := []byte(valid.Get("signature"))
+	tampered[0] ^= 1
+
+	tests := []struct {
+		name   string
+		signer urlSigner
+		key    string
+		query  url.Values
+		want   bool
+	}{
+		{"valid", signer, key, valid, true},
+		{"key lain", signer, "private/attachments/1/abd", valid, false},
+		{"secret lain", urlSigner{secret: []byte("lain")}, key, valid, false},
+		{"secret kosong", urlSigner{}, key, valid, false},
+		{"tanpa query", signer, key, url.Values{}, false},
+		{"expires bukan angka", signer, key, url.Values{"expires": {"x"}, "signature": valid["signature"]}, false},
+		{"expires diubah", signer, key, url.Values{"expires": {future}, "signature": valid["signature"]}, false},
+		{"signature diubah", signer, key, url.Values{"expires": valid["expires"], "signature": {string(tampered)}}, false},
+		{"kedaluwarsa", signer, key, url.Values{
+			"expires":   {strconv.FormatInt(past, 10)},
+			"signature": {signer.signature(key, past)},
+		}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.signer.verify(tt.key, tt.query); got != tt.want {
+				t.Errorf("verify = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
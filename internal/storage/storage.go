@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidKey = errors.New("storage: key tidak valid")
+	ErrNotFound   = errors.New("storage: file tidak ditemukan")
+)
+
+// PrivatePrefix menandai file yang hanya bisa diunduh lewat URL bertanda tangan, misalnya lampiran pesan
+const PrivatePrefix = "private/"
+
+// Storage menyimpan file yang diunggah user. Key selalu memakai "/" apa pun driver-nya.
+type Storage interface {
+	Put(key string, data []byte, contentType string) error
+	Get(key string) ([]byte, error)
+	Delete(key string) error
+	URL(key string) string
+	// SignedURL membuat URL sementara untuk file private, berlaku selama expires
+	SignedURL(key string, expires time.Duration) (string, error)
+}
+
+// FromEnv memilih driver lewat STORAGE_DRIVER, default local
+func FromEnv() (Storage, error) {
+	publicURL := os.Getenv("STORAGE_PUBLIC_URL")
+	// URL bertanda tangan memakai secret tersendiri, jika kosong memakai JWT_SECRET
+	secret := os.Getenv("STORAGE_SIGNING_KEY")
+	if secret == "" {
+		secret = os.Getenv("JWT_SECRET")
+	}
+
+	switch driver := os.Getenv("STORAGE_DRIVER"); driver {
+	case "", "local":
+		dir := os.Getenv("STORAGE_LOCAL_DIR")
+		if dir == "" {
+			dir = "uploads"
+		}
+		if publicURL == "" {
+			publicURL = MediaPrefix
+		}
+		return NewLocal(dir, publicURL, secret), nil
+	case "s3":
+		return NewS3(S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Region:    os.Getenv("S3_REGION"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			PublicURL: publicURL,
+		})
+	case "memory":
+		if publicURL == "" {
+			publicURL = MediaPrefix
+		}
+		return NewMemory(publicURL, secret), nil
+	default:
+		return nil, fmt.Errorf("storage: driver %q tidak dikenal", driver)
+	}
+}
+
+// cleanKey menolak key yang keluar dari root penyimpanan
+func cleanKey(key string) (string, error) {
+	cleaned := path.Clean("/" + key)[1:]
+	if cleaned == "" || cleaned != key || strings.HasPrefix(cleaned, "../") {
+		return "", ErrInvalidKey
+	}
+	return cleaned, nil
+}
@@ -0,0 +1,158 @@
+package storage
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSecret = "rahasia"
+
+func testDrivers(t *testing.T) map[string]Storage {
+	s3, err := NewS3(S3Config{
+		Endpoint:  newFakeS3(t).URL,
+		Bucket:    "chat",
+		AccessKey: awsExample.AccessKey,
+		SecretKey: awsExample.SecretKey,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return map[string]Storage{
+		"memory": NewMemory(MediaPrefix, testSecret),
+		"local":  NewLocal(t.TempDir(), MediaPrefix, testSecret),
+		"s3":     s3,
+	}
+}
+
+func TestStorage(t *testing.T) {
+	for name, store := range testDrivers(t) {
+		t.Run(name, func(t *testing.T) {
+			key := "private/attachments/1/abc"
+			data := []byte("isi lampiran")
+
+			if err := store.Put(key, data, "text/plain"); err != nil {
+				t.Fatalf("put: %v", err)
+			}
+			got, err := store.Get(key)
+			if err != nil {
+				t.Fatalf("get: %v", err)
+			}
+			if !bytes.Equal(got, data) {
+				t.Errorf("get = %q, want %q", got, data)
+			}
+
+			// file yang ditulis ulang diganti isinya
+			if err := store.Put(key, []byte("baru"), "text/plain"); err != nil {
+				t.Fatalf("put ulang: %v", err)
+			}
+			if got, _ := store.Get(key); string(got) != "baru" {
+				t.Errorf("get setelah put ulang = %q", got)
+			}
+
+			signed, err := store.SignedURL(key, time.Minute)
+			if err != nil {
+				t.Fatalf("signed url: %v", err)
+			}
+			if !strings.Contains(signed, "/private/attachments/1/abc?") {
+				t.Errorf("signed url = %s", signed)
+			}
+			if url := store.URL("groups/1/avatar.png"); !strings.HasSuffix(url, "/groups/1/avatar.png") {
+				t.Errorf("url = %s", url)
+			}
+
+			if err := store.Delete(key); err != nil {
+				t.Fatalf("delete: %v", err)
+			}
+			if _, err := store.Get(key); err != ErrNotFound {
+				t.Errorf("get setelah delete err = %v, want ErrNotFound", err)
+			}
+			if err := store.Delete(key); err != nil {
+				t.Errorf("delete file yang sudah tidak ada err = %v", err)
+			}
+		})
+	}
+}
+
+func TestStorageInvalidKey(t *testing.T) {
+	for name, store := range testDrivers(t) {
+		t.Run(name, func(t *testing.T) {
+			for _, key := range []string{"", "../x", "a/../../x", "/abs", "a//b", "a/"} {
+				if err := store.Put(key, []byte("x"), ""); err != ErrInvalidKey {
+					t.Errorf("put %q err = %v, want ErrInvalidKey", key, err)
+				}
+				if _, err := store.SignedURL(key, time.Minute); err != ErrInvalidKey {
+					t.Errorf("signed url %q err = %v, want ErrInvalidKey", key, err)
+				}
+			}
+		})
+	}
+}
+
+func TestMediaHandler(t *testing.T) {
+	handlers := map[string]interface {
+		Storage
+		Handler() http.Handler
+	}{
+		"memory": NewMemory(MediaPrefix, testSecret),
+		"local":  NewLocal(t.TempDir(), MediaPrefix, testSecret),
+	}
+
+	for name, store := range handlers {
+		t.Run(name, func(t *testing.T) {
+			private := "private/attachments/1/abc"
+			public := "groups/1/avatar.png"
+			store.Put(private, []byte("rahasia"), "text/plain")
+			store.Put(public, []byte("publik"), "image/png")
+
+			valid, _ := store.SignedURL(private, time.Minute)
+			expired, _ := store.SignedURL(private, -time.Minute)
+			otherKey, _ := store.SignedURL("private/attachments/1/lain", time.Minute)
+			parsed, _ := url.Parse(valid)
+			query := parsed.Query()
+			query.Set("signature", strings.Repeat("0", 64))
+			tampered := parsed.Path + "?" + query.Encode()
+			query = parsed.Query()
+			query.Set("expires", "9999999999")
+			extended := parsed.Path + "?" + query.Encode()
+
+			tests := []struct {
+				name   string
+				target string
+				status int
+			}{
+				{"signed", valid, http.StatusOK},
+				{"tanpa signature", MediaPrefix + private, http.StatusNotFound},
+				{"kedaluwarsa", expired, http.StatusNotFound},
+				{"signature diubah", tampered, http.StatusNotFound},
+				{"expires diperpanjang", extended, http.StatusNotFound},
+				{"signature key lain", MediaPrefix + private + "?" + strings.SplitN(otherKey, "?", 2)[1], http.StatusNotFound},
+				{"path traversal", MediaPrefix + "groups/../" + private, http.StatusNotFound},
+				{"publik", MediaPrefix + public, http.StatusOK},
+				{"folder", MediaPrefix + "groups/1/", http.StatusNotFound},
+			}
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					rec := httptest.NewRecorder()
+					store.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+					if rec.Code != tt.status {
+						t.Fatalf("status = %d, want %d", rec.Code, tt.status)
+					}
+					if tt.name == "signed" {
+						if rec.Body.String() != "rahasia" {
+							t.Errorf("body = %q", rec.Body.String())
+						}
+						if rec.Header().Get("X-Content-Type-Options") != "nosniff" || rec.Header().Get("Content-Security-Policy") == "" {
+							t.Errorf("header keamanan file private tidak diset: %v", rec.Header())
+						}
+					}
+				})
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"api_chat_ws/dto"
+	"api_chat_ws/helper/utils"
+	"api_chat_ws/model"
+	"strings"
+	"time"
+)
+
+const searchLimit = 50
+
+func (u *chatUsecase) ArchiveGroup(adminId, groupId uint, archive bool) error {
+	if err := u.ensureNotDirect(groupId); err != nil {
+		return err
+	}
+
+	admin, err := u.repo.GetMember(adminId)
+	if err != nil {
+		return err
+	}
+	if model.RoleRank(admin.Role) < model.RoleRank(model.RoleAdmin) {
+		return utils.ErrNotAdmin
+	}
+
+	var archivedAt *time.Time
+	action := model.AuditGroupUnarchive
+	if archive {
+		now := time.Now()
+		archivedAt = &now
+		action = model.AuditGroupArchive
+	}
+	if err := u.repo.UpdateGroupSettings(groupId, map[string]interface{}{"archived_at": archivedAt}); err != nil {
+		return err
+	}
+
+	u.audit(groupId, admin.UserID, action, nil, nil, nil)
+	return nil
+}
+
+// RestoreGroup memakai user id karena grup yang dihapus tidak bisa diakses lewat GetMemberId
+func (u *chatUsecase) RestoreGroup(userId, groupId uint) error {
+	group, err := u.repo.GetDeletedGroup(groupId)
+	if err != nil {
+		return err
+	}
+
+	owner, err := u.repo.GetMemberByUser(groupId, userId)
+	if err != nil {
+		return err
+	}
+	if owner == nil {
+		return utils.ErrNotMember
+	}
+	if owner.Role != model.RoleOwner {
+		return utils.ErrNotOwner
+	}
+
+	grace := time.Duration(u.config.GroupDeleteGraceDays) * 24 * time.Hour
+	if time.Since(group.DeletedAt.Time) > grace {
+		return utils.ErrGraceExpired
+	}
+
+	if err := u.repo.RestoreGroup(groupId); err != nil {
+		return err
+	}
+
+	u.audit(groupId, userId, model.AuditGroupRestore, nil, nil, nil)
+	return nil
+}
+
+// PurgeDeletedGroups menghapus permanen grup yang masa tenggangnya sudah lewat,
+// pesannya dihapus per batch dulu supaya tabel chats tidak terkunci lama
+func (u *chatUsecase) PurgeDeletedGroups() (int, error) {
+	deletedBefore := time.Now().AddDate(0, 0, -u.config.GroupDeleteGraceDays)
+	groups, err := u.repo.GetPurgeableGroups(deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, groupId := range groups {
+		for {
+			deleted, err := u.repo.PurgeChatBatch(groupId, time.Now(), purgeBatchSize)
+			if err != nil {
+				return i, err
+			}
+			if deleted < purgeBatchSize {
+				break
+			}
+			time.Sleep(purgeBatchWait)
+		}
+
+		group, err := u.repo.GetDeletedGroup(groupId)
+		if err != nil {
+			return i, err
+		}
+		if err := u.repo.HardDeleteGroup(groupId); err != nil {
+			return i, err
+		}
+		u.deleteGroupImage(GroupImageAvatar, group.AvatarKey)
+		u.deleteGroupImage(GroupImageBanner, group.BannerKey)
+	}
+
+	return len(groups), nil
+}
+
+func (u *chatUsecase) SearchChats(userId uint, query string, groupId uint) ([]dto.SearchResult, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return []dto.SearchResult{}, nil
+	}
+
+	return u.repo.SearchChats(userId, query, groupId, searchLimit)
+}
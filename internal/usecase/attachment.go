@@ -0,0 +1,337 @@
+package usecase
+
+import (
+	"api_chat_ws/dto"
+	"api_chat_ws/helper/utils"
+	"api_chat_ws/internal/storage"
+	"api_chat_ws/model"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	maxAttachmentsPerChat = 10
+	// lampiran yang belum dipakai pesan kedaluwarsa lalu dibersihkan worker
+	attachmentPendingTTL = 24 * time.Hour
+	attachmentURLTTL     = 15 * time.Minute
+)
+
+// attachmentRules menggabungkan batas server dan grup, grup hanya bisa memperketat.
+// Ukuran 0 dan daftar tipe kosong berarti tanpa batas.
+func (u *chatUsecase) attachmentRules(group *model.ChatGroup) (int64, [][]string) {
+	maxMB := u.config.MaxAttachmentMB
+	if group.AttachmentMaxMB > 0 && (maxMB == 0 || group.AttachmentMaxMB < maxMB) {
+		maxMB = group.AttachmentMaxMB
+	}
+
+	var types [][]string
+	if len(u.config.AttachmentTypes) > 0 {
+		types = append(types, u.config.AttachmentTypes)
+	}
+	if groupTypes := utils.SplitList(group.AttachmentTypes); len(groupTypes) > 0 {
+		types = append(types, groupTypes)
+	}
+	return int64(maxMB) << 20, types
+}
+
+// allowedType mencocokkan content type dengan setiap daftar, misalnya "image/*" atau "application/pdf"
+func allowedType(lists [][]string, contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	for _, list := range lists {
+		matched := false
+		for _, pattern := range list {
+			if pattern == "*/*" || pattern == mediaType ||
+				(strings.HasSuffix(pattern, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(pattern, "*"))) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+func (u *chatUsecase) checkAttachment(lists [][]string, maxBytes int64, size int64, contentType string) error {
+	if maxBytes > 0 && size > maxBytes {
+		return utils.ErrAttachmentTooLarge
+	}
+	if !allowedType(lists, contentType) {
+		return utils.ErrAttachmentType
+	}
+	return nil
+}
+
+// validateAttachmentLimit memeriksa dan merapikan batas lampiran di request ubah grup
+func (u *chatUsecase) validateAttachmentLimit(req *dto.UpdateGroupReq) error {
+	if req.AttachmentMaxMB != nil {
+		if *req.AttachmentMaxMB < 0 || (u.config.MaxAttachmentMB > 0 && *req.AttachmentMaxMB > u.config.MaxAttachmentMB) {
+			return utils.ErrInvalidAttachmentLimit
+		}
+	}
+	if req.AttachmentTypes == nil {
+		return nil
+	}
+
+	types := make([]string, 0, len(*req.AttachmentTypes))
+	for _, t := range *req.AttachmentTypes {
+		t = strings.ToLower(strings.TrimSpace(t))
+		major, minor, ok := strings.Cut(t, "/")
+		if !ok || major == "" || minor == "" || strings.ContainsAny(t, ", ;") || strings.Contains(minor, "/") {
+			return utils.ErrInvalidAttachmentLimit
+		}
+		types = append(types, t)
+	}
+	if len(strings.Join(types, ",")) > 255 {
+		return utils.ErrInvalidAttachmentLimit
+	}
+	*req.AttachmentTypes = types
+	return nil
+}
+
+func attachmentName(name string) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.TrimSpace(strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, name))
+	if name == "" || name == "." || name == ".." || name == "/" {
+		return "file"
+	}
+	for utf8.RuneCountInString(name) > 255 {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	return name
+}
+
+func attachmentKey(groupId uint) (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%sattachments/%d/%s", storage.PrivatePrefix, groupId, hex.EncodeToString(random)), nil
+}
+
+// storeAttachment menyimpan file lalu mencatatnya sebagai lampiran yang belum dipakai
+func (u *chatUsecase) storeAttachment(member *model.GroupMember, name, contentType string, data []byte) (*model.Attachment, error) {
+	key, err := attachmentKey(member.GroupID)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.storage.Put(key, data, contentType); err != nil {
+		return nil, err
+	}
+
+	attachment := model.Attachment{
+		GroupID:     member.GroupID,
+		MemberID:    &member.ID,
+		StorageKey:  key,
+		Name:        name,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+	}
+	if err := u.repo.CreateAttachment(&attachment); err != nil {
+		u.storage.Delete(key)
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+// UploadAttachment menerima file sebelum pesannya dikirim. Content type ditentukan dari isi file,
+// bukan dari header client.
+func (u *chatUsecase) UploadAttachment(req *dto.AttachmentUploadReq) (*dto.AttachmentResponse, error) {
+	member, err := u.checkPermission(req.MemberId, model.PermSendAttachments)
+	if err != nil {
+		return nil, err
+	}
+	group, err := u.ensureCanPost(member)
+	if err != nil {
+		return nil, err
+	}
+
+	maxBytes, types := u.attachmentRules(group)
+	reader := req.File
+	if maxBytes > 0 {
+		reader = io.LimitReader(req.File, maxBytes+1)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, utils.ErrEmptyAttachment
+	}
+
+	contentType := http.DetectContentType(data)
+	if err := u.checkAttachment(types, maxBytes, int64(len(data)), contentType); err != nil {
+		return nil, err
+	}
+
+	attachment, err := u.storeAttachment(member, attachmentName(req.Name), contentType, data)
+	if err != nil {
+		return nil, err
+	}
+
+	response := dto.AttachmentResponse{
+		ID:          attachment.ID,
+		Name:        attachment.Name,
+		ContentType: attachment.ContentType,
+		Size:        attachment.Size,
+	}
+	return &response, nil
+}
+
+// GetAttachmentURL membuat URL unduhan sementara. Lampiran yang belum dikirim hanya bisa dilihat pengunggahnya.
+func (u *chatUsecase) GetAttachmentURL(memberId, groupId, attachmentId uint) (*dto.AttachmentURL, error) {
+	attachment, err := u.repo.GetAttachment(attachmentId)
+	if err != nil {
+		return nil, err
+	}
+	if attachment.GroupID != groupId {
+		return nil, utils.ErrAttachmentNotFound
+	}
+	if attachment.ChatID == nil && (attachment.MemberID == nil || *attachment.MemberID != memberId) {
+		return nil, utils.ErrAttachmentNotFound
+	}
+	// lampiran pesan yang sudah dihapus untuk semua tidak bisa diunduh lagi walaupun file-nya belum dibersihkan
+	if attachment.ChatID != nil {
+		chat, err := u.repo.GetChat(*attachment.ChatID)
+		if err == utils.ErrChatNotFound || (err == nil && chat.DeletedAt != nil) {
+			return nil, utils.ErrAttachmentNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	url, err := u.storage.SignedURL(attachment.StorageKey, attachmentURLTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.AttachmentURL{
+		URL:       url,
+		ExpiresAt: time.Now().Add(attachmentURLTTL),
+	}, nil
+}
+
+// validateAttachments memastikan lampiran milik pengirim, di grup yang sama dan belum dipakai pesan lain
+func (u *chatUsecase) validateAttachments(member *model.GroupMember, attachmentIds []uint) ([]model.Attachment, error) {
+	if len(attachmentIds) == 0 {
+		return nil, nil
+	}
+	if len(attachmentIds) > maxAttachmentsPerChat {
+		return nil, utils.ErrTooManyAttachments
+	}
+	allowed, err := u.hasPermission(member, model.PermSendAttachments)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, utils.ErrForbidden
+	}
+
+	attachments, err := u.repo.GetAttachments(attachmentIds)
+	if err != nil {
+		return nil, err
+	}
+	// id yang dobel atau tidak ada membuat jumlahnya berbeda
+	if len(attachments) != len(attachmentIds) {
+		return nil, utils.ErrInvalidAttachment
+	}
+	for _, a := range attachments {
+		if a.GroupID != member.GroupID || a.ChatID != nil || a.MemberID == nil || *a.MemberID != member.ID ||
+			time.Since(a.CreatedAt) > attachmentPendingTTL {
+			return nil, utils.ErrInvalidAttachment
+		}
+	}
+
+	return attachments, nil
+}
+
+// copyAttachments menyalin lampiran pesan yang diteruskan ke grup tujuan dengan batas grup tujuan
+func (u *chatUsecase) copyAttachments(member *model.GroupMember, group *model.ChatGroup, chatId uint) ([]model.Attachment, error) {
+	sources, err := u.repo.GetChatAttachments(chatId)
+	if err != nil || len(sources) == 0 {
+		return nil, err
+	}
+	allowed, err := u.hasPermission(member, model.PermSendAttachments)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, utils.ErrForbidden
+	}
+
+	maxBytes, types := u.attachmentRules(group)
+	copies := make([]model.Attachment, 0, len(sources))
+	for _, source := range sources {
+		err := u.checkAttachment(types, maxBytes, source.Size, source.ContentType)
+		var data []byte
+		if err == nil {
+			data, err = u.storage.Get(source.StorageKey)
+		}
+		var attachment *model.Attachment
+		if err == nil {
+			attachment, err = u.storeAttachment(member, source.Name, source.ContentType, data)
+		}
+		if err != nil {
+			u.removeAttachments(copies)
+			return nil, err
+		}
+		copies = append(copies, *attachment)
+	}
+
+	return copies, nil
+}
+
+// removeAttachments menghapus file lalu datanya. Yang file-nya gagal dihapus dibiarkan untuk dicoba lagi oleh worker.
+func (u *chatUsecase) removeAttachments(attachments []model.Attachment) error {
+	ids := make([]uint, 0, len(attachments))
+	var failed error
+	for _, a := range attachments {
+		if err := u.storage.Delete(a.StorageKey); err != nil {
+			failed = err
+			continue
+		}
+		ids = append(ids, a.ID)
+	}
+	if err := u.repo.DeleteAttachments(ids); err != nil {
+		return err
+	}
+	return failed
+}
+
+// PurgeOrphanAttachments membersihkan lampiran yang tidak pernah dikirim, yang pesannya sudah dihapus,
+// atau yang grupnya sudah dihapus permanen
+func (u *chatUsecase) PurgeOrphanAttachments() (int, error) {
+	purged := 0
+	for {
+		attachments, err := u.repo.GetOrphanAttachments(time.Now().Add(-attachmentPendingTTL), purgeBatchSize)
+		if err != nil {
+			return purged, err
+		}
+		if err := u.removeAttachments(attachments); err != nil {
+			return purged, err
+		}
+		purged += len(attachments)
+		if len(attachments) < purgeBatchSize {
+			return purged, nil
+		}
+	}
+}
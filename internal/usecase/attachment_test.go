@@ -0,0 +1,88 @@
+package usecase
+
+import (
+	"api_chat_ws/model"
+	"strings"
+	"testing"
+)
+
+func TestAttachmentName(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"biasa", "laporan.pdf", "laporan.pdf"},
+		{"path unix", "../../etc/passwd", "passwd"},
+		{"path windows", "C:\\Users\\budi\\foto.png", "foto.png"},
+		{"karakter kontrol", "a\x00b\nc\x7f.txt", "abc.txt"},
+		{"spasi", "  catatan.txt  ", "catatan.txt"},
+		{"kosong", "", "file"},
+		{"titik", ".", "file"},
+		{"naik folder", "../", "file"},
+		{"hanya slash", "/", "file"},
+		{"terlalu panjang", strings.Repeat("é", 300), strings.Repeat("é", 255)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := attachmentName(tt.in); got != tt.want {
+				t.Errorf("attachmentName(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllowedType(t *testing.T) {
+	server := []string{"image/*", "text/plain", "application/pdf"}
+	tests := []struct {
+		name        string
+		lists       [][]string
+		contentType string
+		want        bool
+	}{
+		{"tanpa batas", nil, "application/zip", true},
+		{"wildcard", [][]string{server}, "image/png", true},
+		{"persis", [][]string{server}, "application/pdf", true},
+		{"dengan parameter", [][]string{server}, "text/plain; charset=utf-8", true},
+		{"tidak ada di daftar", [][]string{server}, "application/zip", false},
+		{"prefix mirip", [][]string{{"image/*"}}, "imagex/png", false},
+		{"semua tipe", [][]string{{"*/*"}}, "application/zip", true},
+		{"grup memperketat", [][]string{server, {"image/png"}}, "image/gif", false},
+		{"lolos server dan grup", [][]string{server, {"image/png"}}, "image/png", true},
+		{"grup tidak bisa memperluas", [][]string{server, {"application/zip"}}, "application/zip", false},
+		{"content type rusak", [][]string{server}, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := allowedType(tt.lists, tt.contentType); got != tt.want {
+				t.Errorf("allowedType(%v, %q) = %v, want %v", tt.lists, tt.contentType, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAttachmentRules(t *testing.T) {
+	u := &chatUsecase{config: ChatConfig{MaxAttachmentMB: 10, AttachmentTypes: []string{"image/*"}}}
+
+	tests := []struct {
+		name      string
+		group     model.ChatGroup
+		wantBytes int64
+		wantLists int
+	}{
+		{"batas server", model.ChatGroup{}, 10 << 20, 1},
+		{"grup lebih ketat", model.ChatGroup{AttachmentMaxMB: 2, AttachmentTypes: "image/png"}, 2 << 20, 2},
+		{"grup tidak bisa lebih longgar", model.ChatGroup{AttachmentMaxMB: 50}, 10 << 20, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			maxBytes, lists := u.attachmentRules(&tt.group)
+			if maxBytes != tt.wantBytes || len(lists) != tt.wantLists {
+				t.Errorf("attachmentRules = %d %v, want %d dan %d daftar", maxBytes, lists, tt.wantBytes, tt.wantLists)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"api_chat_ws/dto"
+	"api_chat_ws/helper/utils"
+	"api_chat_ws/model"
+	"encoding/json"
+	"log"
+)
+
+const (
+	defaultAuditLimit = 20
+	maxAuditLimit     = 100
+)
+
+func toAuditValue(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(raw)
+}
+
+// audit tidak menggagalkan aksi yang sudah berhasil, error cukup dicatat
+func (u *chatUsecase) audit(groupId, actorId uint, action string, targetId *uint, before, after interface{}) {
+	err := u.repo.CreateAudit(&model.GroupAudit{
+		GroupID:  groupId,
+		ActorID:  actorId,
+		Action:   action,
+		TargetID: targetId,
+		Before:   toAuditValue(before),
+		After:    toAuditValue(after),
+	})
+	if err != nil {
+		log.Printf("audit %s grup %d : %v", action, groupId, err)
+	}
+}
+
+func (u *chatUsecase) GetAudit(memberId uint, filter *dto.AuditFilter) (*dto.AuditPage, error) {
+	admin, err := u.repo.GetMember(memberId)
+	if err != nil {
+		return nil, err
+	}
+	if model.RoleRank(admin.Role) < model.RoleRank(model.RoleAdmin) {
+		return nil, utils.ErrNotAdmin
+	}
+
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit < 1 {
+		filter.Limit = defaultAuditLimit
+	}
+	if filter.Limit > maxAuditLimit {
+		filter.Limit = maxAuditLimit
+	}
+
+	audits, total, err := u.repo.GetAudits(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]dto.AuditResponse, 0, len(audits))
+	for _, a := range audits {
+		item := dto.AuditResponse{
+			ID:        a.ID,
+			GroupId:   a.GroupID,
+			ActorId:   a.ActorID,
+			Action:    a.Action,
+			TargetId:  a.TargetID,
+			CreatedAt: a.CreatedAt,
+		}
+		if a.Before != "" {
+			item.Before = json.RawMessage(a.Before)
+		}
+		if a.After != "" {
+			item.After = json.RawMessage(a.After)
+		}
+		items = append(items, item)
+	}
+
+	return &dto.AuditPage{
+		Items: items,
+		Page:  filter.Page,
+		Limit: filter.Limit,
+		Total: total,
+	}, nil
+}
@@ -2,147 +2,693 @@ package usecase
 
 import (
 	"api_chat_ws/dto"
+	"api_chat_ws/helper/utils"
 	"api_chat_ws/internal/repository"
+	"api_chat_ws/internal/storage"
+	"api_chat_ws/model"
 
 	"encoding/json"
 	"fmt"
+	"slices"
+	"time"
 )
 
 type ChatUsecase interface {
 	CreateGroup(req *dto.CreateGroupReq) error
 	UpdateGroup(req *dto.UpdateGroupReq) error
 	DeleteGroup(adminId, groupId uint) error
-	AddMember(req *dto.AddMemberReq) error
-	RemoveMember(req []uint, adminId uint) error
-	ExitGroup(memberId uint) error
-	UpdateRoleUser(req *dto.UpdateRoleMember) error
+	AddMember(req *dto.AddMemberReq) ([]byte, error)
+	ImportMembers(req *dto.ImportMembersReq) (*dto.ImportReport, [][]byte, error)
+	RemoveMember(req *dto.RemoveMemberReq) ([]byte, error)
+	ExitGroup(memberId, groupId uint) ([]byte, error)
+	UpdateRoleUser(req *dto.UpdateRoleMember) ([]byte, error)
+	TransferOwnership(req *dto.TransferOwnershipReq) ([]byte, error)
+	GetMemberList(groupId uint) ([]dto.MemberResponse, error)
+	SetNickname(req *dto.NicknameReq) error
+	GetPermissions(groupId uint) (map[string]map[string]bool, error)
+	UpdatePermissions(req *dto.UpdatePermissionReq) error
+	GetAudit(memberId uint, filter *dto.AuditFilter) (*dto.AuditPage, error)
+	GetRetention(groupId uint) (*dto.RetentionResponse, error)
+	UpdateRetention(req *dto.RetentionReq) (*dto.RetentionResponse, error)
+	PurgeExpiredChats() (int64, error)
+
+	Sanction(req *dto.SanctionReq) (*dto.SanctionResponse, []byte, error)
+	LiftSanction(req *dto.LiftSanctionReq) (*dto.SanctionResponse, error)
+	GetSanctions(memberId, groupId uint) ([]dto.SanctionResponse, error)
+	ExpireSanctions() ([]dto.SanctionResponse, error)
+
+	OpenDirect(userId, targetId uint) (*dto.GroupResponse, error)
+	GetInbox(userId uint, archived bool) ([]dto.InboxItem, error)
+
+	ArchiveGroup(adminId, groupId uint, archive bool) error
+	RestoreGroup(userId, groupId uint) error
+	PurgeDeletedGroups() (int, error)
+	SearchChats(userId uint, query string, groupId uint) ([]dto.SearchResult, error)
+
+	GetTopics(memberId, groupId uint) ([]dto.TopicResponse, error)
+	CreateTopic(req *dto.TopicReq) (*dto.TopicResponse, error)
+	RenameTopic(req *dto.TopicReq) (*dto.TopicResponse, error)
+	ValidateTopic(groupId uint, topicId *uint) error
+
+	GetCapabilities(userId uint) (*dto.Capabilities, error)
+	UploadGroupImage(req *dto.GroupImageReq) (map[string]string, error)
+	DeleteGroupImage(req *dto.GroupImageReq) error
 
 	GetMemberId(id, groupId uint) (uint, error)
-	LoadGroupChat(groupId uint) ([]byte, error)
+	LoadGroupChat(memberId, groupId uint, topicId *uint) ([]byte, error)
+	GetMessages(req *dto.MessagePageReq) (*dto.MessagePage, error)
+	GetThread(req *dto.MessagePageReq) (*dto.ThreadPage, error)
+	GetThreads(memberId, groupId uint) ([]dto.ResponseChat, error)
+	GetThreadInfo(rootId uint) (*dto.ThreadInfo, error)
+	React(req *dto.ReactionReq) (*dto.ReactionEvent, error)
+	SetPinned(req *dto.PinReq) (*dto.PinEvent, []byte, error)
+	GetPins(memberId, groupId uint) ([]dto.ResponseChat, error)
+	UploadAttachment(req *dto.AttachmentUploadReq) (*dto.AttachmentResponse, error)
+	GetAttachmentURL(memberId, groupId, attachmentId uint) (*dto.AttachmentURL, error)
+	PurgeOrphanAttachments() (int, error)
 	GetMembers(groupId uint) ([]uint, error)
-	CreateChat(userID, groupID uint, message string, status []dto.MemberStatus) ([]byte, error)
+	CreateChat(req *dto.CreateChatReq, status []dto.MemberStatus) ([]byte, *dto.MentionEvent, error)
 	UpdateChat(chatId, memberId uint, message string) ([]byte, error)
-	DeleteChat(memberId, id uint) ([]byte, error)
-	UpdateStatusChat(memberId uint) error
+	ForwardChat(req *dto.ForwardReq, status []dto.MemberStatus) ([]byte, error)
+	GetEditHistory(groupId, chatId uint) (*dto.ChatEditHistory, error)
+	DeleteChat(req *dto.DeleteChatReq) ([]byte, error)
+	UpdateStatusChat(memberId uint, topicId *uint) error
 }
 
 type chatUsecase struct {
-	repo repository.ChatRepo
+	repo    repository.ChatRepo
+	config  ChatConfig
+	limiter *rateLimiter
+	storage storage.Storage
 }
 
-func NewChatUsecase(r repository.ChatRepo) ChatUsecase {
-	return &chatUsecase{r}
+func NewChatUsecase(r repository.ChatRepo, config ChatConfig, store storage.Storage) ChatUsecase {
+	limiter := newRateLimiter(config.MessageRateLimit, time.Duration(config.MessageRateWindow)*time.Second)
+	return &chatUsecase{r, config, limiter, store}
 }
 
 func (u *chatUsecase) CreateGroup(req *dto.CreateGroupReq) error {
-	return u.repo.CreateGroup(req)
+	if err := u.checkCreateQuota(req.UserId); err != nil {
+		return err
+	}
+
+	group, err := u.repo.CreateGroup(req)
+	if err != nil {
+		return err
+	}
+
+	u.audit(group.ID, req.UserId, model.AuditGroupCreate, nil, nil, req)
+	return nil
 }
 
 func (u *chatUsecase) UpdateGroup(req *dto.UpdateGroupReq) error {
-	valid, err := u.repo.IsMemberAdmin(req.MemberId)
+	if err := u.ensureManageable(req.GroupId); err != nil {
+		return err
+	}
+
+	member, err := u.checkPermission(req.MemberId, model.PermEditInfo)
 	if err != nil {
 		return err
 	}
-	if !valid {
-		return fmt.Errorf("you arent admin")
+	// mengubah mode pengumuman dan slow mode menentukan siapa yang boleh mengirim, jadi khusus admin,
+	// begitu juga izin menampilkan penulis asli saat pesan diteruskan dan batas lampiran
+	adminOnly := req.Announcement != nil || req.SlowMode != nil || req.ForwardAuthor != nil ||
+		req.AttachmentMaxMB != nil || req.AttachmentTypes != nil
+	if adminOnly && model.RoleRank(member.Role) < model.RoleRank(model.RoleAdmin) {
+		return utils.ErrNotAdmin
+	}
+	if req.SlowMode != nil && (*req.SlowMode < 0 || *req.SlowMode > 21600) {
+		return utils.ErrInvalidSlowMode
+	}
+	if err := u.validateAttachmentLimit(req); err != nil {
+		return err
+	}
+
+	before, err := u.repo.GetGroup(req.GroupId)
+	if err != nil {
+		return err
 	}
 
-	return u.repo.UpdateGroup(req)
+	if err := u.repo.UpdateGroup(req); err != nil {
+		return err
+	}
+
+	u.audit(req.GroupId, member.UserID, model.AuditGroupUpdate, nil, map[string]interface{}{
+		"name":              before.Name,
+		"desc":              before.Description,
+		"announcement":      before.IsAnnouncement,
+		"slow_mode":         before.SlowModeSeconds,
+		"forward_author":    before.ForwardAuthor,
+		"attachment_max_mb": before.AttachmentMaxMB,
+		"attachment_types":  before.AttachmentTypes,
+	}, req)
+	return nil
 }
 
 func (u *chatUsecase) DeleteGroup(adminId, groupId uint) error {
-	valid, err := u.repo.IsMemberAdmin(adminId)
+	if err := u.ensureNotDirect(groupId); err != nil {
+		return err
+	}
+
+	owner, err := u.repo.GetMember(adminId)
 	if err != nil {
 		return err
 	}
-	if !valid {
-		return fmt.Errorf("you arent admin")
+	if owner.Role != model.RoleOwner {
+		return utils.ErrNotOwner
+	}
+
+	if err := u.repo.DeleteGroup(groupId); err != nil {
+		return err
 	}
 
-	return u.repo.DeleteGroup(groupId)
+	u.audit(groupId, owner.UserID, model.AuditGroupDelete, nil, nil, nil)
+	return nil
 
 }
 
-func (u *chatUsecase) AddMember(req *dto.AddMemberReq) error {
-	valid, err := u.repo.IsMemberAdmin(req.AdminId)
+func (u *chatUsecase) AddMember(req *dto.AddMemberReq) ([]byte, error) {
+	if err := u.ensureManageable(req.GroupId); err != nil {
+		return nil, err
+	}
+
+	admin, err := u.checkPermission(req.AdminId, model.PermAddMembers)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	// id yang diulang dan user yang sudah jadi member tidak ikut dihitung ke kapasitas dan kuota
+	slices.Sort(req.UserIds)
+	req.UserIds = slices.Compact(req.UserIds)
+	existing, err := u.repo.GetMemberUserIds(req.GroupId, req.UserIds)
+	if err != nil {
+		return nil, err
+	}
+	req.UserIds = slices.DeleteFunc(req.UserIds, func(id uint) bool {
+		return slices.Contains(existing, id)
+	})
+	if len(req.UserIds) == 0 {
+		return nil, nil
+	}
+
+	banned, err := u.repo.GetBannedUsers(req.GroupId, req.UserIds)
+	if err != nil {
+		return nil, err
+	}
+	if len(banned) > 0 {
+		return nil, utils.ErrUserBanned
+	}
+	if err := u.checkJoinQuota(req.GroupId, req.UserIds); err != nil {
+		return nil, err
+	}
+
+	if err := u.repo.AddMember(req); err != nil {
+		return nil, err
+	}
+
+	for i := range req.UserIds {
+		u.audit(req.GroupId, admin.UserID, model.AuditMemberAdd, &req.UserIds[i], nil, map[string]string{"role": model.RoleMember})
+	}
+	return u.systemMessage(req.GroupId, dto.SystemInfo{
+		Action:    model.AuditMemberAdd,
+		ActorId:   admin.UserID,
+		TargetIds: req.UserIds,
+		Role:      model.RoleMember,
+	}), nil
+}
+
+func (u *chatUsecase) RemoveMember(req *dto.RemoveMemberReq) ([]byte, error) {
+	if err := u.ensureManageable(req.GroupId); err != nil {
+		return nil, err
+	}
+
+	admin, err := u.repo.GetMember(req.AdminId)
+	if err != nil {
+		return nil, err
+	}
+	if model.RoleRank(admin.Role) < model.RoleRank(model.RoleAdmin) {
+		return nil, utils.ErrNotAdmin
+	}
+
+	// id yang diulang tidak membuat jumlah target berbeda
+	slices.Sort(req.UserIds)
+	req.UserIds = slices.Compact(req.UserIds)
+
+	targets, err := u.repo.GetMembersByIds(req.GroupId, req.UserIds)
+	if err != nil {
+		return nil, err
+	}
+	if len(targets) != len(req.UserIds) {
+		return nil, utils.ErrTargetNotMember
+	}
+	for _, t := range targets {
+		if model.RoleRank(t.Role) >= model.RoleRank(admin.Role) {
+			return nil, utils.ErrRoleTooHigh
+		}
+	}
+
+	if err := u.repo.RemoveMember(req.GroupId, req.UserIds); err != nil {
+		return nil, err
+	}
+
+	removed := make([]uint, 0, len(targets))
+	for i := range targets {
+		u.audit(req.GroupId, admin.UserID, model.AuditMemberRemove, &targets[i].UserID, map[string]string{"role": targets[i].Role}, nil)
+		removed = append(removed, targets[i].UserID)
+	}
+	return u.systemMessage(req.GroupId, dto.SystemInfo{
+		Action:    model.AuditMemberRemove,
+		ActorId:   admin.UserID,
+		TargetIds: removed,
+	}), nil
+}
+
+func (u *chatUsecase) ExitGroup(memberId, groupId uint) ([]byte, error) {
+	if err := u.ensureManageable(groupId); err != nil {
+		return nil, err
+	}
+
+	member, err := u.repo.GetMember(memberId)
+	if err != nil {
+		return nil, err
+	}
+
+	successor, err := u.repo.ExitGroup(memberId)
+	if err != nil {
+		return nil, err
+	}
+
+	info := dto.SystemInfo{
+		Action:  model.AuditMemberExit,
+		ActorId: member.UserID,
+	}
+	u.audit(groupId, member.UserID, model.AuditMemberExit, nil, map[string]string{"role": member.Role}, nil)
+	if successor != nil {
+		u.audit(groupId, member.UserID, model.AuditRoleUpdate, &successor.UserID, nil, map[string]string{"role": model.RoleOwner})
+		info.TargetIds = []uint{successor.UserID}
+		info.Role = model.RoleOwner
+	}
+	return u.systemMessage(groupId, info), nil
+}
+
+func (u *chatUsecase) UpdateRoleUser(req *dto.UpdateRoleMember) ([]byte, error) {
+	if err := u.ensureManageable(req.GroupId); err != nil {
+		return nil, err
+	}
+
+	// owner hanya bisa berpindah lewat TransferOwnership
+	if !model.IsValidRole(req.Role) || req.Role == model.RoleOwner {
+		return nil, utils.ErrInvalidRole
+	}
+
+	admin, err := u.repo.GetMember(req.AdminId)
+	if err != nil {
+		return nil, err
+	}
+	if model.RoleRank(admin.Role) < model.RoleRank(model.RoleAdmin) {
+		return nil, utils.ErrNotAdmin
+	}
+
+	target, err := u.repo.GetMember(req.MemberId)
+	if err != nil || target.GroupID != req.GroupId {
+		return nil, utils.ErrTargetNotMember
+	}
+
+	rank := model.RoleRank(admin.Role)
+	if model.RoleRank(target.Role) >= rank || model.RoleRank(req.Role) >= rank {
+		return nil, utils.ErrRoleTooHigh
+	}
+
+	if err := u.repo.UpdateRoleUser(req.MemberId, req.Role); err != nil {
+		return nil, err
+	}
+
+	u.audit(req.GroupId, admin.UserID, model.AuditRoleUpdate, &target.UserID, map[string]string{"role": target.Role}, map[string]string{"role": req.Role})
+	return u.systemMessage(req.GroupId, dto.SystemInfo{
+		Action:    model.AuditRoleUpdate,
+		ActorId:   admin.UserID,
+		TargetIds: []uint{target.UserID},
+		Role:      req.Role,
+	}), nil
+}
+
+func (u *chatUsecase) TransferOwnership(req *dto.TransferOwnershipReq) ([]byte, error) {
+	if err := u.ensureManageable(req.GroupId); err != nil {
+		return nil, err
+	}
+
+	owner, err := u.repo.GetMember(req.OwnerId)
+	if err != nil {
+		return nil, err
 	}
-	if !valid {
-		return fmt.Errorf("you arent admin")
+	if owner.Role != model.RoleOwner {
+		return nil, utils.ErrNotOwner
 	}
 
-	return u.repo.AddMember(req)
+	target, err := u.repo.GetMember(req.MemberId)
+	if err != nil || target.GroupID != req.GroupId || target.ID == owner.ID {
+		return nil, utils.ErrTargetNotMember
+	}
+
+	if err := u.repo.TransferOwnership(owner.ID, target.ID); err != nil {
+		return nil, err
+	}
+
+	u.audit(req.GroupId, owner.UserID, model.AuditOwnershipTransfer, &target.UserID, map[string]string{"role": target.Role}, map[string]string{"role": model.RoleOwner})
+	return u.systemMessage(req.GroupId, dto.SystemInfo{
+		Action:    model.AuditOwnershipTransfer,
+		ActorId:   owner.UserID,
+		TargetIds: []uint{target.UserID},
+		Role:      model.RoleOwner,
+	}), nil
 }
 
-func (u *chatUsecase) RemoveMember(req []uint, adminId uint) error {
-	valid, err := u.repo.IsMemberAdmin(adminId)
+// chat pribadi tidak punya admin, nama, maupun anggota tambahan
+// ensureNotDirect meneruskan ErrGroupNotFound dari GetGroup, handler memetakannya ke 404
+func (u *chatUsecase) ensureNotDirect(groupId uint) error {
+	group, err := u.repo.GetGroup(groupId)
 	if err != nil {
 		return err
 	}
-	if !valid {
-		return fmt.Errorf("you arent admin")
+	if group.Type == model.GroupTypeDirect {
+		return utils.ErrDirectChat
 	}
 
-	return u.repo.RemoveMember(req)
+	return nil
 }
 
-func (u *chatUsecase) ExitGroup(memberId uint) error {
-	return u.repo.ExitGroup(memberId)
+// grup arsip hanya bisa dibaca, semua aksi yang mengubah grup atau isinya ditolak
+// kecuali membuka arsip dan menghapus grup
+func (u *chatUsecase) ensureNotArchived(groupId uint) (*model.ChatGroup, error) {
+	group, err := u.repo.GetGroup(groupId)
+	if err != nil {
+		return nil, err
+	}
+	if group.ArchivedAt != nil {
+		return nil, utils.ErrGroupArchived
+	}
+
+	return group, nil
 }
 
-func (u *chatUsecase) UpdateRoleUser(req *dto.UpdateRoleMember) error {
-	valid, err := u.repo.IsMemberAdmin(req.AdminId)
+// ensureManageable dipakai aksi pengelolaan grup, gabungan ensureNotDirect dan ensureNotArchived
+func (u *chatUsecase) ensureManageable(groupId uint) error {
+	group, err := u.ensureNotArchived(groupId)
 	if err != nil {
 		return err
 	}
-	if !valid {
-		return fmt.Errorf("you arent admin")
+	if group.Type == model.GroupTypeDirect {
+		return utils.ErrDirectChat
+	}
+
+	return nil
+}
+
+// grup arsip hanya bisa dibaca, di grup pengumuman member biasa juga hanya bisa membaca,
+// begitu juga member yang di-mute
+func (u *chatUsecase) ensureCanPost(member *model.GroupMember) (*model.ChatGroup, error) {
+	group, err := u.ensureCanReact(member)
+	if err != nil {
+		return nil, err
+	}
+	if group.IsAnnouncement && model.RoleRank(member.Role) < model.RoleRank(model.RoleAdmin) {
+		return nil, utils.ErrAnnouncementOnly
+	}
+
+	return group, nil
+}
+
+// ensureCanReact hanya mengecek arsip dan mute, member grup pengumuman tetap boleh memberi reaksi
+func (u *chatUsecase) ensureCanReact(member *model.GroupMember) (*model.ChatGroup, error) {
+	group, err := u.ensureNotArchived(member.GroupID)
+	if err != nil {
+		return nil, err
+	}
+
+	muted, err := u.repo.GetActiveSanction(member.GroupID, member.UserID, model.SanctionMute)
+	if err != nil {
+		return nil, err
+	}
+	if muted != nil {
+		return nil, utils.ErrMuted
+	}
+
+	return group, nil
+}
+
+func (u *chatUsecase) OpenDirect(userId, targetId uint) (*dto.GroupResponse, error) {
+	if userId == targetId {
+		return nil, utils.ErrSelfChat
+	}
+
+	target, err := u.repo.GetUser(targetId)
+	if err != nil {
+		return nil, err
 	}
 
-	return u.repo.UpdateRoleUser(req.MemberId, req.Role)
+	low, high := userId, targetId
+	if low > high {
+		low, high = high, low
+	}
+	key := fmt.Sprintf("%d:%d", low, high)
+
+	group, err := u.repo.FindDirectGroup(key)
+	if err != nil {
+		return nil, err
+	}
+	if group == nil {
+		group, err = u.repo.CreateDirectGroup(key, []uint{low, high})
+		if err != nil {
+			// request lain bisa saja membuat chat yang sama lebih dulu
+			existing, findErr := u.repo.FindDirectGroup(key)
+			if findErr != nil || existing == nil {
+				return nil, err
+			}
+			group = existing
+		}
+	}
+
+	return &dto.GroupResponse{
+		GroupId: group.ID,
+		Type:    group.Type,
+		Name:    target.Username,
+		Peer: &dto.UserBrief{
+			UserId:   target.ID,
+			Username: target.Username,
+		},
+	}, nil
+}
+
+func (u *chatUsecase) GetInbox(userId uint, archived bool) ([]dto.InboxItem, error) {
+	inbox, err := u.repo.GetInbox(userId, archived)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range inbox {
+		inbox[i].Avatar = u.groupImageURLs(GroupImageAvatar, inbox[i].AvatarKey)
+		inbox[i].Banner = u.groupImageURLs(GroupImageBanner, inbox[i].BannerKey)
+	}
+	return inbox, nil
 }
 
 func (u *chatUsecase) GetMembers(groupId uint) ([]uint, error) {
 	return u.repo.GetGroupMembers(groupId)
 }
 
-func (u *chatUsecase) CreateChat(memberId, groupID uint, message string, status []dto.MemberStatus) ([]byte, error) {
+// CreateChat juga mengembalikan event mention untuk user yang di-mention, nil jika tidak ada
+func (u *chatUsecase) CreateChat(req *dto.CreateChatReq, status []dto.MemberStatus) ([]byte, *dto.MentionEvent, error) {
+	if err := u.checkMessageLength(req.Message); err != nil {
+		return nil, nil, err
+	}
 
-	chat, err := u.repo.CreateChat(memberId, groupID, message, status)
+	member, err := u.checkPermission(req.MemberId, model.PermSendMessage)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	group, err := u.ensureCanPost(member)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := u.ValidateTopic(req.GroupId, req.TopicId); err != nil {
+		return nil, nil, err
+	}
+	if err := u.validateReply(req); err != nil {
+		return nil, nil, err
+	}
+	if err := u.validateThread(req); err != nil {
+		return nil, nil, err
+	}
+	mentions, recipients, err := u.resolveMentions(member, req.Message, status)
+	if err != nil {
+		return nil, nil, err
+	}
+	attachments, err := u.validateAttachments(member, req.AttachmentIds)
+	if err != nil {
+		return nil, nil, err
+	}
+	release, err := u.checkRateLimit(member, group)
+	if err != nil {
+		return nil, nil, err
 	}
 
+	newChat := model.Chat{
+		GroupMemberID: &member.ID,
+		GroupID:       req.GroupId,
+		Type:          model.ChatTypeMessage,
+		Message:       req.Message,
+		Mentions:      mentions,
+		Attachments:   attachments,
+	}
+	if req.TopicId != nil && *req.TopicId != 0 {
+		newChat.TopicID = req.TopicId
+	}
+	if req.ParentId != nil && *req.ParentId != 0 {
+		newChat.ParentID = req.ParentId
+	}
+	if req.ThreadId != nil && *req.ThreadId != 0 {
+		newChat.ThreadRootID = req.ThreadId
+		newChat.AlsoSent = req.AlsoSend
+	}
+
+	chat, err := u.repo.CreateChat(&newChat, status)
+	if err != nil {
+		release()
+		return nil, nil, err
+	}
+	chat.DisplayName = u.displayName(member)
+
 	response, _ := json.Marshal(&chat)
-	return response, nil
+	if len(recipients) == 0 {
+		return response, nil, nil
+	}
+	return response, &dto.MentionEvent{
+		GroupId: req.GroupId,
+		TopicId: req.TopicId,
+		Chat:    response,
+		UserIds: recipients,
+	}, nil
 }
 
 func (u *chatUsecase) UpdateChat(chatId, memberId uint, message string) ([]byte, error) {
-	chat, err := u.repo.UpdateChat(chatId, memberId, message)
+	if err := u.checkMessageLength(message); err != nil {
+		return nil, err
+	}
+
+	member, err := u.checkPermission(memberId, model.PermSendMessage)
 	if err != nil {
 		return nil, err
 	}
+	if _, err := u.ensureCanPost(member); err != nil {
+		return nil, err
+	}
 
-	response, _ := json.Marshal(&chat)
-	return response, nil
-}
-func (u *chatUsecase) DeleteChat(memberId, id uint) ([]byte, error) {
-	chat, err := u.repo.DeleteChat(memberId, id)
+	chat, err := u.repo.GetChat(chatId)
 	if err != nil {
 		return nil, err
 	}
-	response, _ := json.Marshal(&chat)
+	if chat.DeletedAt != nil {
+		return nil, utils.ErrChatNotFound
+	}
+	if chat.GroupMemberID == nil || *chat.GroupMemberID != memberId || chat.Type != model.ChatTypeMessage {
+		return nil, utils.ErrrnotChat
+	}
+	if u.config.EditWindowMinutes > 0 && time.Since(chat.CreatedAt) > time.Duration(u.config.EditWindowMinutes)*time.Minute {
+		return nil, utils.ErrEditWindowExpired
+	}
+
+	if chat.Message != message {
+		if err := u.repo.UpdateChat(chat, message); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := u.repo.GetChatResponse(chatId, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	response, _ := json.Marshal(&result)
 	return response, nil
 }
 
-func (u *chatUsecase) LoadGroupChat(groupId uint) ([]byte, error) {
-	chats, err := u.repo.LoadGroupChat(groupId)
+// DeleteChat menghapus pesan untuk semua member, atau hanya menyembunyikannya jika ForMe.
+// Moderator ke atas bisa menghapus pesan member dengan role di bawahnya, tercatat di audit.
+func (u *chatUsecase) DeleteChat(req *dto.DeleteChatReq) ([]byte, error) {
+	chat, err := u.repo.GetChat(req.ChatId)
+	if err != nil {
+		return nil, err
+	}
+	if chat.GroupID != req.GroupId {
+		return nil, utils.ErrChatNotFound
+	}
+
+	if req.ForMe {
+		if err := u.repo.HideChat(chat.ID, req.MemberId); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	if chat.DeletedAt != nil {
+		return nil, utils.ErrChatNotFound
+	}
+	if _, err := u.ensureNotArchived(chat.GroupID); err != nil {
+		return nil, err
+	}
+	member, err := u.repo.GetMember(req.MemberId)
+	if err != nil {
+		return nil, err
+	}
+
+	own := chat.GroupMemberID != nil && *chat.GroupMemberID == member.ID
+	var author *model.GroupMember
+	if !own {
+		if model.RoleRank(member.Role) < model.RoleRank(model.RoleModerator) {
+			return nil, utils.ErrrnotChat
+		}
+		// penulis yang sudah keluar grup tidak perlu dicek role-nya
+		if chat.GroupMemberID != nil {
+			author, err = u.repo.GetMember(*chat.GroupMemberID)
+			if err != nil && err != utils.ErrNotMember {
+				return nil, err
+			}
+			if author != nil && model.RoleRank(author.Role) >= model.RoleRank(member.Role) {
+				return nil, utils.ErrRoleTooHigh
+			}
+		}
+	}
+
+	attachments, err := u.repo.GetChatAttachments(chat.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.repo.DeleteChat(chat, member.ID); err != nil {
+		return nil, err
+	}
+	// file yang gagal dihapus sekarang tetap dibersihkan worker karena sudah dilepas dari pesan
+	u.removeAttachments(attachments)
+
+	if !own {
+		var target *uint
+		if author != nil {
+			target = &author.UserID
+		}
+		// isi pesan tidak disimpan supaya tidak bisa dibaca lagi lewat audit dan tetap ikut aturan retensi
+		u.audit(chat.GroupID, member.UserID, model.AuditChatDelete, target, map[string]interface{}{
+			"chat_id":   chat.ID,
+			"author_id": chat.GroupMemberID,
+		}, map[string]interface{}{
+			"deleted_by": member.ID,
+		})
+	}
+
+	result, err := u.repo.GetChatResponse(chat.ID, 0)
 	if err != nil {
 		return nil, err
 	}
 
-	response, _ := json.Marshal(&chats)
+	response, _ := json.Marshal(&result)
 	return response, nil
 }
 
@@ -150,6 +696,6 @@ func (u *chatUsecase) GetMemberId(id, groupId uint) (uint, error) {
 	return u.repo.GetMemberId(id, groupId)
 }
 
-func (u *chatUsecase) UpdateStatusChat(memberId uint) error {
-	return u.repo.UpdateStatusChat(memberId)
+func (u *chatUsecase) UpdateStatusChat(memberId uint, topicId *uint) error {
+	return u.repo.UpdateStatusChat(memberId, topicId)
 }
@@ -0,0 +1,42 @@
+package usecase
+
+import "api_chat_ws/helper/utils"
+
+// ChatConfig berisi pengaturan tingkat server, dibaca dari env saat start
+type ChatConfig struct {
+	DefaultRetentionDays int
+	GroupDeleteGraceDays int
+	// batas pesan per member dalam satu window, 0 berarti tanpa batas
+	MessageRateLimit  int
+	MessageRateWindow int
+	// batas dan kuota, 0 berarti tanpa batas
+	MaxMembersPerGroup int
+	MaxGroupsCreated   int
+	MaxGroupsJoined    int
+	MaxMessageLength   int
+	// batas waktu edit pesan dalam menit sejak dikirim, 0 berarti tanpa batas
+	EditWindowMinutes int
+	MaxPinsPerGroup   int
+	// batas lampiran server, grup hanya bisa memperketat
+	MaxAttachmentMB int
+	AttachmentTypes []string
+}
+
+func LoadChatConfig() ChatConfig {
+	return ChatConfig{
+		DefaultRetentionDays: utils.GetEnvInt("DEFAULT_RETENTION_DAYS", 0),
+		GroupDeleteGraceDays: utils.GetEnvInt("GROUP_DELETE_GRACE_DAYS", 30),
+		MessageRateLimit:     utils.GetEnvInt("MESSAGE_RATE_LIMIT", 10),
+		MessageRateWindow:    utils.GetEnvInt("MESSAGE_RATE_WINDOW_SECONDS", 10),
+		MaxMembersPerGroup:   utils.GetEnvInt("MAX_MEMBERS_PER_GROUP", 1000),
+		MaxGroupsCreated:     utils.GetEnvInt("MAX_GROUPS_CREATED_PER_USER", 100),
+		MaxGroupsJoined:      utils.GetEnvInt("MAX_GROUPS_JOINED_PER_USER", 500),
+		MaxMessageLength:     utils.GetEnvInt("MAX_MESSAGE_LENGTH", 4000),
+		EditWindowMinutes:    utils.GetEnvInt("MESSAGE_EDIT_WINDOW_MINUTES", 0),
+		MaxPinsPerGroup:      utils.GetEnvInt("MAX_PINS_PER_GROUP", 50),
+		MaxAttachmentMB:      utils.GetEnvInt("MAX_ATTACHMENT_MB", 10),
+		AttachmentTypes: utils.GetEnvList("ATTACHMENT_TYPES", []string{
+			"image/*", "video/*", "audio/*", "application/pdf", "application/zip", "text/plain",
+		}),
+	}
+}
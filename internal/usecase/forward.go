@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"api_chat_ws/dto"
+	"api_chat_ws/helper/utils"
+	"api_chat_ws/model"
+	"encoding/json"
+)
+
+// ForwardChat menyalin pesan ke grup lain yang juga diikuti user, dengan aturan kirim grup tujuan.
+// Asal pesan hanya dicatat jika grup asal mengizinkan penulis aslinya ditampilkan.
+// Lampiran disalin sebagai file baru supaya tidak ikut terhapus bersama pesan aslinya.
+func (u *chatUsecase) ForwardChat(req *dto.ForwardReq, status []dto.MemberStatus) ([]byte, error) {
+	chat, err := u.repo.GetChat(req.ChatId)
+	if err != nil {
+		return nil, err
+	}
+	if chat.GroupID != req.GroupId || chat.Type != model.ChatTypeMessage || chat.DeletedAt != nil {
+		return nil, utils.ErrChatNotFound
+	}
+	source, err := u.repo.GetGroup(chat.GroupID)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := u.repo.GetMemberByUser(req.TargetGroupId, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if target == nil {
+		return nil, utils.ErrForwardTarget
+	}
+	member, err := u.checkPermission(target.ID, model.PermSendMessage)
+	if err != nil {
+		return nil, err
+	}
+	group, err := u.ensureCanPost(member)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.ValidateTopic(req.TargetGroupId, req.TopicId); err != nil {
+		return nil, err
+	}
+	release, err := u.checkRateLimit(member, group)
+	if err != nil {
+		return nil, err
+	}
+
+	attachments, err := u.copyAttachments(member, group, chat.ID)
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	newChat := model.Chat{
+		GroupMemberID: &member.ID,
+		GroupID:       req.TargetGroupId,
+		Type:          model.ChatTypeMessage,
+		Message:       chat.Message,
+		Forwarded:     true,
+		Attachments:   attachments,
+	}
+	if req.TopicId != nil && *req.TopicId != 0 {
+		newChat.TopicID = req.TopicId
+	}
+	if source.ForwardAuthor {
+		// pesan terusan yang diteruskan lagi tetap menunjuk ke pesan aslinya
+		if chat.Forwarded {
+			newChat.ForwardChatID = chat.ForwardChatID
+			newChat.ForwardGroupID = chat.ForwardGroupID
+			newChat.ForwardUserID = chat.ForwardUserID
+		} else {
+			newChat.ForwardChatID = &chat.ID
+			newChat.ForwardGroupID = &chat.GroupID
+			if chat.GroupMemberID != nil {
+				author, err := u.repo.GetMember(*chat.GroupMemberID)
+				if err != nil && err != utils.ErrNotMember {
+					u.removeAttachments(attachments)
+					release()
+					return nil, err
+				}
+				if author != nil {
+					newChat.ForwardUserID = &author.UserID
+				}
+			}
+		}
+	}
+
+	result, err := u.repo.CreateChat(&newChat, status)
+	if err != nil {
+		u.removeAttachments(attachments)
+		release()
+		return nil, err
+	}
+	result.DisplayName = u.displayName(member)
+
+	response, _ := json.Marshal(&result)
+	return response, nil
+}
@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"api_chat_ws/dto"
+	"api_chat_ws/helper/utils"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 100
+)
+
+// cursor berisi created_at dan id pesan supaya urutan tetap stabil walau ada pesan dengan waktu yang sama
+func encodeCursor(chat *dto.ResponseChat) string {
+	raw := fmt.Sprintf("%d_%d", chat.CreatedAt.UnixNano(), chat.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(cursor string) (*dto.ChatCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, utils.ErrInvalidCursor
+	}
+
+	var nano int64
+	var id uint
+	if _, err := fmt.Sscanf(string(raw), "%d_%d", &nano, &id); err != nil {
+		return nil, utils.ErrInvalidCursor
+	}
+
+	return &dto.ChatCursor{CreatedAt: time.Unix(0, nano), ID: id}, nil
+}
+
+// GetMessages mengembalikan satu halaman riwayat. Tanpa before/after yang diambil halaman terbaru.
+func (u *chatUsecase) GetMessages(req *dto.MessagePageReq) (*dto.MessagePage, error) {
+	if req.Before != "" && req.After != "" {
+		return nil, utils.ErrInvalidCursor
+	}
+	if err := u.ValidateTopic(req.GroupId, req.TopicId); err != nil {
+		return nil, err
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+	limit = min(limit, maxPageSize)
+
+	older := req.After == ""
+	var cursor *dto.ChatCursor
+	if raw := req.Before + req.After; raw != "" {
+		decoded, err := decodeCursor(raw)
+		if err != nil {
+			return nil, err
+		}
+		cursor = decoded
+	}
+
+	// ambil satu lebih banyak untuk mengetahui apakah masih ada halaman berikutnya
+	filter := dto.ChatFilter{
+		ViewerId: req.MemberId,
+		GroupId:  req.GroupId,
+		TopicId:  req.TopicId,
+		ThreadId: req.ThreadId,
+	}
+	items, err := u.repo.GetChatPage(&filter, cursor, older, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	hasMore := len(items) > limit
+	if hasMore && older {
+		items = items[1:]
+	} else if hasMore {
+		items = items[:limit]
+	}
+
+	page := dto.MessagePage{Items: items}
+	if len(items) == 0 {
+		return &page, nil
+	}
+
+	first, last := &items[0], &items[len(items)-1]
+	if older {
+		if hasMore {
+			page.Before = encodeCursor(first)
+		}
+		if cursor != nil {
+			page.After = encodeCursor(last)
+		}
+	} else {
+		page.Before = encodeCursor(first)
+		if hasMore {
+			page.After = encodeCursor(last)
+		}
+	}
+
+	return &page, nil
+}
+
+// LoadGroupChat dipakai saat koneksi websocket dibuka, hanya halaman terbaru yang dikirim
+func (u *chatUsecase) LoadGroupChat(memberId, groupId uint, topicId *uint) ([]byte, error) {
+	page, err := u.GetMessages(&dto.MessagePageReq{
+		MemberId: memberId,
+		GroupId:  groupId,
+		TopicId:  topicId,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response, _ := json.Marshal(page)
+	return response, nil
+}
@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"api_chat_ws/helper/utils"
+	"bytes"
+	"image"
+	"image/draw"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"net/http"
+)
+
+const (
+	maxImageSize      = 5 << 20
+	maxImageDimension = 6000
+	// batas total piksel, hasil decode RGBA memakan 4 byte per piksel (sekitar 64MB)
+	maxImagePixels = 16_000_000
+)
+
+type imageSize struct {
+	Name   string
+	Width  int
+	Height int
+}
+
+var allowedImageTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+}
+
+// decodeImage memeriksa tipe dari isi file (bukan nama atau header) dan ukuran piksel
+// sebelum decode penuh, supaya gambar raksasa tidak menghabiskan memori
+func decodeImage(data []byte) (*image.RGBA, error) {
+	if len(data) > maxImageSize {
+		return nil, utils.ErrImageTooLarge
+	}
+	if !allowedImageTypes[http.DetectContentType(data)] {
+		return nil, utils.ErrInvalidImage
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, utils.ErrInvalidImage
+	}
+	if config.Width <= 0 || config.Height <= 0 {
+		return nil, utils.ErrInvalidImage
+	}
+	if config.Width > maxImageDimension || config.Height > maxImageDimension || config.Width*config.Height > maxImagePixels {
+		return nil, utils.ErrImageTooLarge
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, utils.ErrInvalidImage
+	}
+
+	rgba := image.NewRGBA(image.Rect(0, 0, src.Bounds().Dx(), src.Bounds().Dy()))
+	draw.Draw(rgba, rgba.Bounds(), src, src.Bounds().Min, draw.Src)
+	return rgba, nil
+}
+
+// coverResize memotong bagian tengah gambar sesuai rasio tujuan lalu mengubah ukurannya,
+// setiap piksel tujuan adalah rata-rata area piksel sumber yang diwakilinya
+func coverResize(src *image.RGBA, width, height int) (*image.RGBA, error) {
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+	if sw <= 0 || sh <= 0 || width <= 0 || height <= 0 {
+		return nil, utils.ErrInvalidImage
+	}
+	cw, ch := sw, sw*height/width
+	if ch > sh {
+		cw, ch = sh*width/height, sh
+	}
+	cw, ch = max(cw, 1), max(ch, 1)
+	x0, y0 := (sw-cw)/2, (sh-ch)/2
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		sy0 := y0 + y*ch/height
+		sy1 := max(y0+(y+1)*ch/height, sy0+1)
+		for x := 0; x < width; x++ {
+			sx0 := x0 + x*cw/width
+			sx1 := max(x0+(x+1)*cw/width, sx0+1)
+
+			var r, g, b, a, n uint32
+			for sy := sy0; sy < sy1; sy++ {
+				off := src.PixOffset(sx0, sy)
+				for sx := sx0; sx < sx1; sx++ {
+					r += uint32(src.Pix[off])
+					g += uint32(src.Pix[off+1])
+					b += uint32(src.Pix[off+2])
+					a += uint32(src.Pix[off+3])
+					off += 4
+					n++
+				}
+			}
+
+			d := dst.PixOffset(x, y)
+			dst.Pix[d] = uint8(r / n)
+			dst.Pix[d+1] = uint8(g / n)
+			dst.Pix[d+2] = uint8(b / n)
+			dst.Pix[d+3] = uint8(a / n)
+		}
+	}
+
+	return dst, nil
+}
+
+func encodePNG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,228 @@
+package usecase
+
+import (
+	"api_chat_ws/dto"
+	"api_chat_ws/helper/utils"
+	"api_chat_ws/model"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+)
+
+const maxImportRows = 1000
+
+// status per baris pada laporan import
+const (
+	importOK            = "ok"
+	importInvalid       = "invalid"
+	importDuplicate     = "duplicate"
+	importNotFound      = "not_found"
+	importAlreadyMember = "already_member"
+	importBanned        = "banned"
+	importGroupFull     = "group_full"
+	importQuotaExceeded = "quota_exceeded"
+)
+
+// parseImportCSV membaca kolom identifier (email atau username) dan role (opsional, default member).
+// Baris header dilewati jika kolom pertamanya bernama email, username atau identifier.
+func parseImportCSV(r io.Reader) ([]dto.ImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	rows := make([]dto.ImportRow, 0)
+	first := true
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, utils.ErrInvalidCSV
+		}
+		line, _ := reader.FieldPos(0)
+
+		identifier := strings.TrimSpace(record[0])
+		if first {
+			first = false
+			switch strings.ToLower(identifier) {
+			case "email", "username", "identifier":
+				continue
+			}
+		}
+
+		role := model.RoleMember
+		if len(record) > 1 && strings.TrimSpace(record[1]) != "" {
+			role = strings.ToLower(strings.TrimSpace(record[1]))
+		}
+
+		rows = append(rows, dto.ImportRow{
+			Line:       line,
+			Identifier: identifier,
+			Role:       role,
+		})
+		if len(rows) > maxImportRows {
+			return nil, utils.ErrImportTooLarge
+		}
+	}
+
+	if len(rows) == 0 {
+		return nil, utils.ErrEmptyImport
+	}
+	return rows, nil
+}
+
+// ImportMembers memvalidasi semua baris dulu, lalu menyimpan baris yang valid dalam satu transaksi.
+// Pada dry run hanya laporan yang dikembalikan. Pesan sistem dibuat per role yang ditambahkan.
+func (u *chatUsecase) ImportMembers(req *dto.ImportMembersReq) (*dto.ImportReport, [][]byte, error) {
+	if err := u.ensureManageable(req.GroupId); err != nil {
+		return nil, nil, err
+	}
+
+	// sama seperti AddMember, role member selalu boleh. Role di atasnya harus di bawah role pengimpor.
+	admin, err := u.checkPermission(req.AdminId, model.PermAddMembers)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, err := parseImportCSV(req.File)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	identifiers := make([]string, 0, len(rows))
+	for _, row := range rows {
+		if row.Identifier != "" {
+			identifiers = append(identifiers, row.Identifier)
+		}
+	}
+	users, err := u.repo.FindUsersByIdentifiers(identifiers)
+	if err != nil {
+		return nil, nil, err
+	}
+	byEmail := make(map[string]uint, len(users))
+	byUsername := make(map[string]uint, len(users))
+	userIds := make([]uint, 0, len(users))
+	for _, user := range users {
+		byEmail[strings.ToLower(user.Email)] = user.ID
+		byUsername[user.Username] = user.ID
+		userIds = append(userIds, user.ID)
+	}
+
+	existing, err := u.repo.GetMemberUserIds(req.GroupId, userIds)
+	if err != nil {
+		return nil, nil, err
+	}
+	banned, err := u.repo.GetBannedUsers(req.GroupId, userIds)
+	if err != nil {
+		return nil, nil, err
+	}
+	joined, err := u.repo.CountGroupsJoined(userIds)
+	if err != nil {
+		return nil, nil, err
+	}
+	memberCount, err := u.repo.CountMembers(req.GroupId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	skip := make(map[uint]string, len(existing)+len(banned))
+	for _, id := range existing {
+		skip[id] = importAlreadyMember
+	}
+	for _, id := range banned {
+		skip[id] = importBanned
+	}
+
+	report := dto.ImportReport{
+		DryRun: req.DryRun,
+		Total:  len(rows),
+		Rows:   rows,
+	}
+	seen := make(map[uint]bool, len(rows))
+	members := make([]model.GroupMember, 0, len(rows))
+	for i := range report.Rows {
+		row := &report.Rows[i]
+		switch {
+		case row.Identifier == "":
+			row.Status, row.Message = importInvalid, "identifier kosong"
+			continue
+		case !model.IsValidRole(row.Role) || row.Role == model.RoleOwner:
+			row.Status, row.Message = importInvalid, utils.ErrInvalidRole.Error()
+			continue
+		case row.Role != model.RoleMember && model.RoleRank(row.Role) >= model.RoleRank(admin.Role):
+			row.Status, row.Message = importInvalid, utils.ErrRoleTooHigh.Error()
+			continue
+		}
+
+		userId, ok := byEmail[strings.ToLower(row.Identifier)]
+		if !ok {
+			userId, ok = byUsername[row.Identifier]
+		}
+		if !ok {
+			row.Status, row.Message = importNotFound, utils.ErrUserNotFound.Error()
+			continue
+		}
+		row.UserId = userId
+
+		if status, ok := skip[userId]; ok {
+			row.Status = status
+			continue
+		}
+		if seen[userId] {
+			row.Status = importDuplicate
+			continue
+		}
+		seen[userId] = true
+
+		if u.config.MaxGroupsJoined > 0 && joined[userId] >= int64(u.config.MaxGroupsJoined) {
+			row.Status, row.Message = importQuotaExceeded, utils.ErrJoinQuota.Error()
+			continue
+		}
+		if u.config.MaxMembersPerGroup > 0 && memberCount+int64(len(members)) >= int64(u.config.MaxMembersPerGroup) {
+			row.Status, row.Message = importGroupFull, utils.ErrGroupFull.Error()
+			continue
+		}
+
+		row.Status = importOK
+		members = append(members, model.GroupMember{
+			GroupID: req.GroupId,
+			UserID:  userId,
+			Role:    row.Role,
+		})
+	}
+	report.Valid = len(members)
+
+	if req.DryRun || len(members) == 0 {
+		return &report, nil, nil
+	}
+
+	if err := u.repo.ImportMembers(members); err != nil {
+		return nil, nil, err
+	}
+	report.Added = len(members)
+
+	byRole := make(map[string][]uint)
+	roles := make([]string, 0)
+	for i := range members {
+		role := members[i].Role
+		if _, ok := byRole[role]; !ok {
+			roles = append(roles, role)
+		}
+		byRole[role] = append(byRole[role], members[i].UserID)
+		u.audit(req.GroupId, admin.UserID, model.AuditMemberAdd, &members[i].UserID, nil, map[string]string{"role": role, "source": "import"})
+	}
+
+	systems := make([][]byte, 0, len(roles))
+	for _, role := range roles {
+		systems = append(systems, u.systemMessage(req.GroupId, dto.SystemInfo{
+			Action:    model.AuditMemberAdd,
+			ActorId:   admin.UserID,
+			TargetIds: byRole[role],
+			Role:      role,
+		}))
+	}
+
+	return &report, systems, nil
+}
@@ -0,0 +1,82 @@
+package usecase
+
+import (
+	"api_chat_ws/dto"
+	"api_chat_ws/helper/utils"
+	"unicode/utf8"
+)
+
+func (u *chatUsecase) GetCapabilities(userId uint) (*dto.Capabilities, error) {
+	created, err := u.repo.CountGroupsCreated(userId)
+	if err != nil {
+		return nil, err
+	}
+	joined, err := u.repo.CountGroupsJoined([]uint{userId})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.Capabilities{
+		MaxMembersPerGroup: u.config.MaxMembersPerGroup,
+		MaxGroupsCreated:   u.config.MaxGroupsCreated,
+		MaxGroupsJoined:    u.config.MaxGroupsJoined,
+		MaxMessageLength:   u.config.MaxMessageLength,
+		MessageRateLimit:   u.config.MessageRateLimit,
+		MessageRateWindow:  u.config.MessageRateWindow,
+		EditWindow:         u.config.EditWindowMinutes,
+		MaxPins:            u.config.MaxPinsPerGroup,
+		MaxAttachmentMB:    u.config.MaxAttachmentMB,
+		AttachmentTypes:    u.config.AttachmentTypes,
+		GroupsCreated:      created,
+		GroupsJoined:       joined[userId],
+	}, nil
+}
+
+func (u *chatUsecase) checkMessageLength(message string) error {
+	if u.config.MaxMessageLength > 0 && utf8.RuneCountInString(message) > u.config.MaxMessageLength {
+		return utils.ErrMessageTooLong
+	}
+	return nil
+}
+
+func (u *chatUsecase) checkCreateQuota(userId uint) error {
+	if u.config.MaxGroupsCreated > 0 {
+		created, err := u.repo.CountGroupsCreated(userId)
+		if err != nil {
+			return err
+		}
+		if created >= int64(u.config.MaxGroupsCreated) {
+			return utils.ErrCreateQuota
+		}
+	}
+
+	return u.checkJoinQuota(0, []uint{userId})
+}
+
+// checkJoinQuota memastikan grup masih muat untuk user baru dan tidak ada user yang melewati
+// kuota grup yang diikuti. groupId 0 berarti grup baru sehingga kapasitas tidak dicek.
+func (u *chatUsecase) checkJoinQuota(groupId uint, userIds []uint) error {
+	if groupId != 0 && u.config.MaxMembersPerGroup > 0 {
+		count, err := u.repo.CountMembers(groupId)
+		if err != nil {
+			return err
+		}
+		if count+int64(len(userIds)) > int64(u.config.MaxMembersPerGroup) {
+			return utils.ErrGroupFull
+		}
+	}
+
+	if u.config.MaxGroupsJoined > 0 {
+		joined, err := u.repo.CountGroupsJoined(userIds)
+		if err != nil {
+			return err
+		}
+		for _, id := range userIds {
+			if joined[id] >= int64(u.config.MaxGroupsJoined) {
+				return utils.ErrJoinQuota
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package usecase
+
+import (
+	"api_chat_ws/dto"
+	"api_chat_ws/helper/utils"
+	"api_chat_ws/model"
+	"strings"
+	"unicode/utf8"
+)
+
+const maxNicknameLength = 64
+
+func (u *chatUsecase) GetMemberList(groupId uint) ([]dto.MemberResponse, error) {
+	members, err := u.repo.GetMemberList(groupId)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.MemberResponse, 0, len(members))
+	for i := range members {
+		response = append(response, dto.MemberResponse{
+			MemberId:    members[i].ID,
+			UserId:      members[i].UserID,
+			Username:    members[i].User.Username,
+			Nickname:    members[i].Nickname,
+			DisplayName: members[i].DisplayName(),
+			Role:        members[i].Role,
+			JoinedAt:    members[i].CreatedAt,
+		})
+	}
+
+	return response, nil
+}
+
+// SetNickname bisa untuk diri sendiri, atau untuk member dengan role di bawahnya jika punya permission
+func (u *chatUsecase) SetNickname(req *dto.NicknameReq) error {
+	nickname := strings.TrimSpace(req.Nickname)
+	if utf8.RuneCountInString(nickname) > maxNicknameLength {
+		return utils.ErrInvalidNickname
+	}
+	if _, err := u.ensureNotArchived(req.GroupId); err != nil {
+		return err
+	}
+
+	if req.MemberId == 0 || req.MemberId == req.ActorId {
+		return u.repo.SetNickname(req.ActorId, nickname)
+	}
+
+	actor, err := u.checkPermission(req.ActorId, model.PermManageNicknames)
+	if err != nil {
+		return err
+	}
+
+	target, err := u.repo.GetMember(req.MemberId)
+	if err != nil || target.GroupID != req.GroupId {
+		return utils.ErrTargetNotMember
+	}
+	if model.RoleRank(target.Role) >= model.RoleRank(actor.Role) {
+		return utils.ErrRoleTooHigh
+	}
+
+	if err := u.repo.SetNickname(target.ID, nickname); err != nil {
+		return err
+	}
+
+	u.audit(req.GroupId, actor.UserID, model.AuditNicknameUpdate, &target.UserID, map[string]string{"nickname": target.Nickname}, map[string]string{"nickname": nickname})
+	return nil
+}
+
+// displayName dipakai untuk payload chat yang dikirim langsung setelah disimpan
+func (u *chatUsecase) displayName(member *model.GroupMember) string {
+	if member.Nickname != "" {
+		return member.Nickname
+	}
+
+	usernames, err := u.repo.GetUsernames([]uint{member.UserID})
+	if err != nil {
+		return ""
+	}
+	return usernames[member.UserID]
+}
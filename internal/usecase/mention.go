@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"api_chat_ws/dto"
+	"api_chat_ws/helper/utils"
+	"api_chat_ws/model"
+)
+
+// resolveMentions mengubah @nama di pesan menjadi daftar member yang di-mention beserta user id-nya.
+// @here hanya mengenai member yang sedang online dan boleh dipakai semua member, @everyone butuh izin
+// mention_everyone. Penulis pesan sendiri tidak ikut dicatat.
+func (u *chatUsecase) resolveMentions(author *model.GroupMember, message string, status []dto.MemberStatus) ([]model.Mention, []uint, error) {
+	tokens := utils.ParseMentions(message)
+	if len(tokens) == 0 {
+		return nil, nil, nil
+	}
+
+	var here, everyone bool
+	names := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		switch token.Name {
+		case model.MentionHere:
+			here = true
+		case model.MentionEveryone:
+			everyone = true
+		default:
+			names = append(names, token.Name)
+		}
+	}
+
+	kinds := make(map[uint]string)
+	userIds := make(map[uint]uint)
+	if len(names) > 0 {
+		members, err := u.repo.FindMembersByUsernames(author.GroupID, names)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, m := range members {
+			kinds[m.ID] = model.MentionUser
+			userIds[m.ID] = m.UserID
+		}
+	}
+
+	if everyone {
+		allowed, err := u.hasPermission(author, model.PermMentionEveryone)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !allowed {
+			return nil, nil, utils.ErrMentionEveryone
+		}
+	}
+
+	if here || everyone {
+
+		online := make(map[uint]bool, len(status))
+		for _, s := range status {
+			online[s.MemberId] = s.Status
+		}
+		members, err := u.repo.GetMemberList(author.GroupID)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, m := range members {
+			if _, ok := kinds[m.ID]; ok {
+				continue
+			}
+			switch {
+			case everyone:
+				kinds[m.ID] = model.MentionEveryone
+			case online[m.ID]:
+				kinds[m.ID] = model.MentionHere
+			default:
+				continue
+			}
+			userIds[m.ID] = m.UserID
+		}
+	}
+	delete(kinds, author.ID)
+
+	mentions := make([]model.Mention, 0, len(kinds))
+	recipients := make([]uint, 0, len(kinds))
+	for memberId, kind := range kinds {
+		mentions = append(mentions, model.Mention{MemberID: memberId, Kind: kind})
+		recipients = append(recipients, userIds[memberId])
+	}
+
+	return mentions, recipients, nil
+}
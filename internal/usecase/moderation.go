@@ -0,0 +1,191 @@
+package usecase
+
+import (
+	"api_chat_ws/dto"
+	"api_chat_ws/helper/utils"
+	"api_chat_ws/model"
+	"time"
+)
+
+func toSanctionResponse(s *model.GroupSanction) dto.SanctionResponse {
+	now := time.Now()
+	return dto.SanctionResponse{
+		GroupId:   s.GroupID,
+		UserId:    s.UserID,
+		Type:      s.Type,
+		Reason:    s.Reason,
+		IssuedBy:  s.IssuedBy,
+		ExpiresAt: s.ExpiresAt,
+		CreatedAt: s.CreatedAt,
+		Active:    s.LiftedAt == nil && (s.ExpiresAt == nil || s.ExpiresAt.After(now)),
+	}
+}
+
+// moderator ke atas bisa memberi sanksi kepada member dengan role di bawahnya
+func (u *chatUsecase) getModerator(memberId uint) (*model.GroupMember, error) {
+	moderator, err := u.repo.GetMember(memberId)
+	if err != nil {
+		return nil, err
+	}
+	if model.RoleRank(moderator.Role) < model.RoleRank(model.RoleModerator) {
+		return nil, utils.ErrForbidden
+	}
+
+	return moderator, nil
+}
+
+func (u *chatUsecase) Sanction(req *dto.SanctionReq) (*dto.SanctionResponse, []byte, error) {
+	if err := u.ensureManageable(req.GroupId); err != nil {
+		return nil, nil, err
+	}
+	if req.Minutes < 0 {
+		return nil, nil, utils.ErrInvalidDuration
+	}
+
+	moderator, err := u.getModerator(req.AdminId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if _, err := u.repo.GetUser(req.UserId); err != nil {
+		return nil, nil, err
+	}
+
+	// user yang belum jadi member tetap bisa di-ban supaya tidak bisa ditambahkan
+	target, err := u.repo.GetMemberByUser(req.GroupId, req.UserId)
+	if err != nil {
+		return nil, nil, err
+	}
+	if target == nil && req.Type == model.SanctionMute {
+		return nil, nil, utils.ErrTargetNotMember
+	}
+	if target != nil && model.RoleRank(target.Role) >= model.RoleRank(moderator.Role) {
+		return nil, nil, utils.ErrRoleTooHigh
+	}
+
+	sanction := model.GroupSanction{
+		GroupID:  req.GroupId,
+		UserID:   req.UserId,
+		Type:     req.Type,
+		Reason:   req.Reason,
+		IssuedBy: moderator.UserID,
+	}
+	if req.Minutes > 0 {
+		expiresAt := time.Now().Add(time.Duration(req.Minutes) * time.Minute)
+		sanction.ExpiresAt = &expiresAt
+	}
+
+	var removeMemberId uint
+	if req.Type == model.SanctionBan && target != nil {
+		removeMemberId = target.ID
+	}
+	if err := u.repo.CreateSanction(&sanction, removeMemberId); err != nil {
+		return nil, nil, err
+	}
+
+	response := toSanctionResponse(&sanction)
+	action := model.AuditMemberMute
+	if req.Type == model.SanctionBan {
+		action = model.AuditMemberBan
+	}
+	u.audit(req.GroupId, moderator.UserID, action, &sanction.UserID, nil, response)
+
+	var system []byte
+	if removeMemberId != 0 {
+		system = u.systemMessage(req.GroupId, dto.SystemInfo{
+			Action:    model.AuditMemberBan,
+			ActorId:   moderator.UserID,
+			TargetIds: []uint{sanction.UserID},
+		})
+	}
+	return &response, system, nil
+}
+
+// LiftSanction memakai aturan rank yang sama dengan Sanction, dan sanksi dari role yang lebih tinggi
+// hanya bisa dicabut oleh role yang setara atau lebih tinggi
+func (u *chatUsecase) LiftSanction(req *dto.LiftSanctionReq) (*dto.SanctionResponse, error) {
+	if err := u.ensureManageable(req.GroupId); err != nil {
+		return nil, err
+	}
+
+	moderator, err := u.getModerator(req.AdminId)
+	if err != nil {
+		return nil, err
+	}
+
+	active, err := u.repo.GetActiveSanction(req.GroupId, req.UserId, req.Type)
+	if err != nil {
+		return nil, err
+	}
+	if active == nil {
+		return nil, utils.ErrNoSanction
+	}
+
+	target, err := u.repo.GetMemberByUser(req.GroupId, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if target != nil && model.RoleRank(target.Role) >= model.RoleRank(moderator.Role) {
+		return nil, utils.ErrRoleTooHigh
+	}
+	// pemberi sanksi yang sudah keluar grup tidak perlu dicek role-nya
+	issuer, err := u.repo.GetMemberByUser(req.GroupId, active.IssuedBy)
+	if err != nil {
+		return nil, err
+	}
+	if issuer != nil && model.RoleRank(issuer.Role) > model.RoleRank(moderator.Role) {
+		return nil, utils.ErrRoleTooHigh
+	}
+
+	sanction, err := u.repo.LiftSanction(req.GroupId, req.UserId, req.Type)
+	if err != nil {
+		return nil, err
+	}
+	if sanction == nil {
+		return nil, utils.ErrNoSanction
+	}
+
+	action := model.AuditMemberUnmute
+	if req.Type == model.SanctionBan {
+		action = model.AuditMemberUnban
+	}
+	u.audit(req.GroupId, moderator.UserID, action, &sanction.UserID, map[string]interface{}{
+		"reason":     sanction.Reason,
+		"expires_at": sanction.ExpiresAt,
+	}, nil)
+
+	response := toSanctionResponse(sanction)
+	return &response, nil
+}
+
+func (u *chatUsecase) GetSanctions(memberId, groupId uint) ([]dto.SanctionResponse, error) {
+	if _, err := u.getModerator(memberId); err != nil {
+		return nil, err
+	}
+
+	sanctions, err := u.repo.GetActiveSanctions(groupId)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.SanctionResponse, 0, len(sanctions))
+	for i := range sanctions {
+		response = append(response, toSanctionResponse(&sanctions[i]))
+	}
+
+	return response, nil
+}
+
+func (u *chatUsecase) ExpireSanctions() ([]dto.SanctionResponse, error) {
+	expired, err := u.repo.ExpireSanctions(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.SanctionResponse, 0, len(expired))
+	for i := range expired {
+		response = append(response, toSanctionResponse(&expired[i]))
+	}
+
+	return response, nil
+}
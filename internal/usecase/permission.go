@@ -0,0 +1,108 @@
+package usecase
+
+import (
+	"api_chat_ws/dto"
+	"api_chat_ws/helper/utils"
+	"api_chat_ws/model"
+)
+
+func (u *chatUsecase) hasPermission(member *model.GroupMember, perm string) (bool, error) {
+	if member.Role == model.RoleOwner {
+		return true, nil
+	}
+
+	allowed, err := u.repo.GetPermission(member.GroupID, member.Role, perm)
+	if err != nil {
+		return false, err
+	}
+	if allowed == nil {
+		return model.DefaultPermission(member.Role, perm), nil
+	}
+
+	return *allowed, nil
+}
+
+// checkPermission mengembalikan member yang bersangkutan supaya pemanggil tidak perlu query ulang
+func (u *chatUsecase) checkPermission(memberId uint, perm string) (*model.GroupMember, error) {
+	member, err := u.repo.GetMember(memberId)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed, err := u.hasPermission(member, perm)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, utils.ErrForbidden
+	}
+
+	return member, nil
+}
+
+func (u *chatUsecase) GetPermissions(groupId uint) (map[string]map[string]bool, error) {
+	saved, err := u.repo.GetPermissions(groupId)
+	if err != nil {
+		return nil, err
+	}
+
+	matrix := make(map[string]map[string]bool)
+	for _, role := range []string{model.RoleOwner, model.RoleAdmin, model.RoleModerator, model.RoleMember} {
+		matrix[role] = make(map[string]bool, len(model.Permissions))
+		for _, perm := range model.Permissions {
+			matrix[role][perm] = model.DefaultPermission(role, perm)
+		}
+	}
+	for _, p := range saved {
+		if p.Role == model.RoleOwner || matrix[p.Role] == nil {
+			continue
+		}
+		matrix[p.Role][p.Permission] = p.Allowed
+	}
+
+	return matrix, nil
+}
+
+func (u *chatUsecase) UpdatePermissions(req *dto.UpdatePermissionReq) error {
+	if err := u.ensureManageable(req.GroupId); err != nil {
+		return err
+	}
+
+	if !model.IsValidRole(req.Role) || req.Role == model.RoleOwner {
+		return utils.ErrInvalidRole
+	}
+	for perm := range req.Permissions {
+		if !model.IsValidPermission(perm) {
+			return utils.ErrInvalidPermission
+		}
+	}
+
+	admin, err := u.repo.GetMember(req.AdminId)
+	if err != nil {
+		return err
+	}
+	if model.RoleRank(admin.Role) < model.RoleRank(model.RoleAdmin) {
+		return utils.ErrNotAdmin
+	}
+	if model.RoleRank(req.Role) >= model.RoleRank(admin.Role) {
+		return utils.ErrRoleTooHigh
+	}
+
+	before, err := u.GetPermissions(req.GroupId)
+	if err != nil {
+		return err
+	}
+
+	if err := u.repo.SetPermissions(req.GroupId, req.Role, req.Permissions); err != nil {
+		return err
+	}
+
+	u.audit(req.GroupId, admin.UserID, model.AuditPermissionUpdate, nil, map[string]interface{}{
+		"role":        req.Role,
+		"permissions": before[req.Role],
+	}, map[string]interface{}{
+		"role":        req.Role,
+		"permissions": req.Permissions,
+	})
+	return nil
+}
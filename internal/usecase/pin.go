@@ -0,0 +1,65 @@
+package usecase
+
+import (
+	"api_chat_ws/dto"
+	"api_chat_ws/helper/utils"
+	"api_chat_ws/model"
+)
+
+// SetPinned menyematkan atau melepas sematan pesan. Menyematkan juga membuat pesan sistem
+// yang dikembalikan untuk di-broadcast.
+func (u *chatUsecase) SetPinned(req *dto.PinReq) (*dto.PinEvent, []byte, error) {
+	member, err := u.checkPermission(req.MemberId, model.PermPinMessages)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := u.ensureNotArchived(member.GroupID); err != nil {
+		return nil, nil, err
+	}
+
+	chat, err := u.repo.GetChat(req.ChatId)
+	if err != nil {
+		return nil, nil, err
+	}
+	if chat.GroupID != req.GroupId || chat.Type != model.ChatTypeMessage || chat.DeletedAt != nil {
+		return nil, nil, utils.ErrChatNotFound
+	}
+
+	if !req.Pin {
+		if chat.PinnedAt == nil {
+			return nil, nil, utils.ErrNotPinned
+		}
+		if err := u.repo.UnpinChat(chat.ID); err != nil {
+			return nil, nil, err
+		}
+		u.audit(req.GroupId, member.UserID, model.AuditChatUnpin, nil, map[string]uint{"chat_id": chat.ID}, nil)
+
+		return &dto.PinEvent{ChatId: chat.ID, MemberId: member.ID}, nil, nil
+	}
+
+	if chat.PinnedAt != nil {
+		return nil, nil, utils.ErrAlreadyPinned
+	}
+	pinnedAt, err := u.repo.PinChat(req.GroupId, chat.ID, member.ID, u.config.MaxPinsPerGroup)
+	if err != nil {
+		return nil, nil, err
+	}
+	u.audit(req.GroupId, member.UserID, model.AuditChatPin, nil, nil, map[string]uint{"chat_id": chat.ID})
+
+	system := u.systemMessage(req.GroupId, dto.SystemInfo{
+		Action:  model.AuditChatPin,
+		ActorId: member.UserID,
+		ChatId:  chat.ID,
+	})
+
+	return &dto.PinEvent{
+		ChatId:   chat.ID,
+		Pinned:   true,
+		MemberId: member.ID,
+		PinnedAt: pinnedAt,
+	}, system, nil
+}
+
+func (u *chatUsecase) GetPins(memberId, groupId uint) ([]dto.ResponseChat, error) {
+	return u.repo.GetPinnedChats(groupId, memberId)
+}
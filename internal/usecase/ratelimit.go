@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"api_chat_ws/helper/utils"
+	"api_chat_ws/model"
+	"sync"
+	"time"
+)
+
+// rateLimiter menghitung pesan per member dalam sliding window, disimpan di memori.
+// Member yang window-nya sudah kosong dihapus dari map supaya tidak tumbuh terus.
+type rateLimiter struct {
+	mu        sync.Mutex
+	limit     int
+	window    time.Duration
+	hits      map[uint][]time.Time
+	lastSweep time.Time
+}
+
+func newRateLimiter(limit int, window time.Duration) *rateLimiter {
+	return &rateLimiter{
+		limit:  limit,
+		window: window,
+		hits:   make(map[uint][]time.Time),
+	}
+}
+
+func (l *rateLimiter) enabled() bool {
+	return l.limit > 0 && l.window > 0
+}
+
+// prune membuang hit di luar window, harus dipanggil dengan mu terkunci
+func (l *rateLimiter) prune(memberId uint, now time.Time) []time.Time {
+	hits := l.hits[memberId]
+	start := 0
+	for start < len(hits) && now.Sub(hits[start]) >= l.window {
+		start++
+	}
+	hits = hits[start:]
+	if len(hits) == 0 {
+		delete(l.hits, memberId)
+		return nil
+	}
+	l.hits[memberId] = hits
+	return hits
+}
+
+// reserve mengecek dan mencatat hit dalam satu langkah supaya dua koneksi member yang sama
+// tidak sama-sama lolos. Hit dilepas lewat release jika pesan gagal disimpan.
+func (l *rateLimiter) reserve(memberId uint, now time.Time) (bool, time.Duration) {
+	if !l.enabled() {
+		return true, 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	hits := l.prune(memberId, now)
+	if len(hits) >= l.limit {
+		return false, l.window - now.Sub(hits[0])
+	}
+	l.hits[memberId] = append(hits, now)
+
+	// sekali per window member lain yang sudah tidak mengirim pesan ikut dibersihkan
+	if now.Sub(l.lastSweep) >= l.window {
+		for id := range l.hits {
+			l.prune(id, now)
+		}
+		l.lastSweep = now
+	}
+	return true, 0
+}
+
+func (l *rateLimiter) release(memberId uint, at time.Time) {
+	if !l.enabled() {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	hits := l.hits[memberId]
+	for i := len(hits) - 1; i >= 0; i-- {
+		if hits[i].Equal(at) {
+			l.hits[memberId] = append(hits[:i], hits[i+1:]...)
+			break
+		}
+	}
+	l.prune(memberId, time.Now())
+}
+
+// checkRateLimit menerapkan slow mode grup dan batas pesan server, admin ke atas dikecualikan.
+// Kuota langsung dipakai, pemanggil wajib memanggil release jika pesan gagal disimpan.
+func (u *chatUsecase) checkRateLimit(member *model.GroupMember, group *model.ChatGroup) (func(), error) {
+	release := func() {}
+	if model.RoleRank(member.Role) >= model.RoleRank(model.RoleAdmin) {
+		return release, nil
+	}
+
+	now := time.Now()
+	if group.SlowModeSeconds > 0 {
+		last, err := u.repo.GetLastChatTime(member.ID)
+		if err != nil {
+			return nil, err
+		}
+		interval := time.Duration(group.SlowModeSeconds) * time.Second
+		if last != nil && now.Sub(*last) < interval {
+			return nil, &utils.RateLimitError{RetryAfter: interval - now.Sub(*last)}
+		}
+	}
+
+	if ok, retryAfter := u.limiter.reserve(member.ID, now); !ok {
+		return nil, &utils.RateLimitError{RetryAfter: retryAfter}
+	}
+
+	return func() { u.limiter.release(member.ID, now) }, nil
+}
@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"api_chat_ws/dto"
+	"api_chat_ws/helper/utils"
+	"api_chat_ws/model"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	maxReactionKinds = 20
+	// emoji gabungan (bendera, warna kulit, keluarga) bisa terdiri dari beberapa rune
+	maxEmojiRunes = 8
+	maxEmojiBytes = 32
+)
+
+func validEmoji(emoji string) bool {
+	if emoji == "" || len(emoji) > maxEmojiBytes || utf8.RuneCountInString(emoji) > maxEmojiRunes {
+		return false
+	}
+	for _, r := range emoji {
+		if unicode.IsSpace(r) || unicode.IsControl(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// React menambah atau menghapus reaksi member, lalu mengembalikan event berisi jumlah terbaru
+func (u *chatUsecase) React(req *dto.ReactionReq) (*dto.ReactionEvent, error) {
+	if !validEmoji(req.Emoji) {
+		return nil, utils.ErrInvalidEmoji
+	}
+
+	chat, err := u.repo.GetChat(req.ChatId)
+	if err != nil {
+		return nil, err
+	}
+	if chat.GroupID != req.GroupId || chat.Type != model.ChatTypeMessage || chat.DeletedAt != nil {
+		return nil, utils.ErrChatNotFound
+	}
+
+	if req.Add {
+		member, err := u.repo.GetMember(req.MemberId)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := u.ensureCanReact(member); err != nil {
+			return nil, err
+		}
+
+		count, err := u.repo.CountReaction(chat.ID, req.Emoji)
+		if err != nil {
+			return nil, err
+		}
+		if count == 0 {
+			kinds, err := u.repo.CountReactionKinds(chat.ID)
+			if err != nil {
+				return nil, err
+			}
+			if kinds >= maxReactionKinds {
+				return nil, utils.ErrTooManyReactions
+			}
+		}
+
+		if err := u.repo.AddReaction(&model.Reaction{
+			ChatID:   chat.ID,
+			MemberID: req.MemberId,
+			Emoji:    req.Emoji,
+		}); err != nil {
+			return nil, err
+		}
+	} else {
+		if _, err := u.ensureNotArchived(chat.GroupID); err != nil {
+			return nil, err
+		}
+		if _, err := u.repo.RemoveReaction(chat.ID, req.MemberId, req.Emoji); err != nil {
+			return nil, err
+		}
+	}
+
+	count, err := u.repo.CountReaction(chat.ID, req.Emoji)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ReactionEvent{
+		ChatId:   chat.ID,
+		MemberId: req.MemberId,
+		Emoji:    req.Emoji,
+		Added:    req.Add,
+		Count:    count,
+	}, nil
+}
@@ -0,0 +1,34 @@
+package usecase
+
+import (
+	"api_chat_ws/dto"
+	"api_chat_ws/helper/utils"
+)
+
+// validateReply memastikan pesan yang dibalas ada di grup dan topic yang sama
+func (u *chatUsecase) validateReply(req *dto.CreateChatReq) error {
+	if req.ParentId == nil || *req.ParentId == 0 {
+		return nil
+	}
+
+	parent, err := u.repo.GetChat(*req.ParentId)
+	if err == utils.ErrChatNotFound {
+		return utils.ErrInvalidReply
+	}
+	if err != nil {
+		return err
+	}
+	if parent.GroupID != req.GroupId || parent.DeletedAt != nil {
+		return utils.ErrInvalidReply
+	}
+
+	var topicId uint
+	if req.TopicId != nil {
+		topicId = *req.TopicId
+	}
+	if chatTopic(parent) != topicId {
+		return utils.ErrInvalidReply
+	}
+
+	return nil
+}
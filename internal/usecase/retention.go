@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"api_chat_ws/dto"
+	"api_chat_ws/helper/utils"
+	"api_chat_ws/model"
+	"time"
+)
+
+const (
+	purgeBatchSize = 500
+	purgeBatchWait = 200 * time.Millisecond
+)
+
+func (u *chatUsecase) effectiveRetention(group *model.ChatGroup) int {
+	if group.RetentionDays == nil {
+		return u.config.DefaultRetentionDays
+	}
+	return *group.RetentionDays
+}
+
+func (u *chatUsecase) GetRetention(groupId uint) (*dto.RetentionResponse, error) {
+	group, err := u.repo.GetGroup(groupId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.RetentionResponse{
+		GroupId:       group.ID,
+		RetentionDays: group.RetentionDays,
+		EffectiveDays: u.effectiveRetention(group),
+		LegalHold:     group.LegalHold,
+	}, nil
+}
+
+func (u *chatUsecase) UpdateRetention(req *dto.RetentionReq) (*dto.RetentionResponse, error) {
+	if req.RetentionDays != nil && *req.RetentionDays < 0 {
+		return nil, utils.ErrInvalidRetention
+	}
+	if _, err := u.ensureNotArchived(req.GroupId); err != nil {
+		return nil, err
+	}
+
+	admin, err := u.repo.GetMember(req.AdminId)
+	if err != nil {
+		return nil, err
+	}
+	if model.RoleRank(admin.Role) < model.RoleRank(model.RoleAdmin) {
+		return nil, utils.ErrNotAdmin
+	}
+	// legal hold menyangkut kepatuhan, hanya owner yang boleh mengubahnya
+	if req.LegalHold != nil && admin.Role != model.RoleOwner {
+		return nil, utils.ErrNotOwner
+	}
+
+	before, err := u.GetRetention(req.GroupId)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := make(map[string]interface{})
+	if req.UseDefault {
+		updated["retention_days"] = nil
+	} else if req.RetentionDays != nil {
+		updated["retention_days"] = *req.RetentionDays
+	}
+	if req.LegalHold != nil {
+		updated["legal_hold"] = *req.LegalHold
+	}
+	if len(updated) > 0 {
+		if err := u.repo.UpdateGroupSettings(req.GroupId, updated); err != nil {
+			return nil, err
+		}
+	}
+
+	after, err := u.GetRetention(req.GroupId)
+	if err != nil {
+		return nil, err
+	}
+
+	u.audit(req.GroupId, admin.UserID, model.AuditRetentionUpdate, nil, before, after)
+	return after, nil
+}
+
+// PurgeExpiredChats dipanggil worker, grup dengan legal hold dilewati
+func (u *chatUsecase) PurgeExpiredChats() (int64, error) {
+	groups, err := u.repo.GetRetentionGroups(u.config.DefaultRetentionDays)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for i := range groups {
+		days := u.effectiveRetention(&groups[i])
+		if days <= 0 {
+			continue
+		}
+		before := time.Now().AddDate(0, 0, -days)
+
+		for {
+			deleted, err := u.repo.PurgeChatBatch(groups[i].ID, before, purgeBatchSize)
+			if err != nil {
+				return total, err
+			}
+			total += int64(deleted)
+			if deleted < purgeBatchSize {
+				break
+			}
+			time.Sleep(purgeBatchWait)
+		}
+	}
+
+	return total, nil
+}
@@ -0,0 +1,44 @@
+package usecase
+
+import (
+	"api_chat_ws/dto"
+	"api_chat_ws/helper/utils"
+)
+
+// GetEditHistory mengembalikan semua isi pesan dari yang pertama sampai yang berlaku sekarang
+func (u *chatUsecase) GetEditHistory(groupId, chatId uint) (*dto.ChatEditHistory, error) {
+	chat, err := u.repo.GetChat(chatId)
+	if err != nil {
+		return nil, err
+	}
+	if chat.GroupID != groupId || chat.DeletedAt != nil {
+		return nil, utils.ErrChatNotFound
+	}
+
+	revisions, err := u.repo.GetRevisions(chat.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	history := dto.ChatEditHistory{
+		ChatId:    chat.ID,
+		Revisions: make([]dto.ChatRevision, 0, len(revisions)+1),
+	}
+	// isi versi berikutnya berlaku sejak versi sebelumnya diganti
+	since := chat.CreatedAt
+	for _, rev := range revisions {
+		replacedAt := rev.CreatedAt
+		history.Revisions = append(history.Revisions, dto.ChatRevision{
+			Message:    rev.Message,
+			CreatedAt:  since,
+			ReplacedAt: &replacedAt,
+		})
+		since = rev.CreatedAt
+	}
+	history.Revisions = append(history.Revisions, dto.ChatRevision{
+		Message:   chat.Message,
+		CreatedAt: since,
+	})
+
+	return &history, nil
+}
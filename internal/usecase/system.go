@@ -0,0 +1,77 @@
+package usecase
+
+import (
+	"api_chat_ws/dto"
+	"api_chat_ws/model"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// keluar-masuk member tidak ditampilkan di grup pengumuman supaya tidak ramai
+func isJoinLeave(action string) bool {
+	switch action {
+	case model.AuditMemberAdd, model.AuditMemberRemove, model.AuditMemberExit, model.AuditMemberBan:
+		return true
+	}
+	return false
+}
+
+func systemText(info *dto.SystemInfo, usernames map[uint]string) string {
+	actor := usernames[info.ActorId]
+	targets := make([]string, 0, len(info.TargetIds))
+	for _, id := range info.TargetIds {
+		targets = append(targets, usernames[id])
+	}
+	target := strings.Join(targets, ", ")
+
+	switch info.Action {
+	case model.AuditMemberAdd:
+		return fmt.Sprintf("%s menambahkan %s", actor, target)
+	case model.AuditMemberRemove:
+		return fmt.Sprintf("%s mengeluarkan %s", actor, target)
+	case model.AuditMemberBan:
+		return fmt.Sprintf("%s mem-ban %s", actor, target)
+	case model.AuditMemberExit:
+		if target != "" {
+			return fmt.Sprintf("%s keluar dari grup, %s sekarang menjadi %s", actor, target, info.Role)
+		}
+		return fmt.Sprintf("%s keluar dari grup", actor)
+	case model.AuditRoleUpdate:
+		return fmt.Sprintf("%s mengubah role %s menjadi %s", actor, target, info.Role)
+	case model.AuditOwnershipTransfer:
+		return fmt.Sprintf("%s menyerahkan kepemilikan grup ke %s", actor, target)
+	case model.AuditChatPin:
+		return fmt.Sprintf("%s menyematkan sebuah pesan", actor)
+	}
+	return ""
+}
+
+// systemMessage menyimpan pesan sistem lalu mengembalikan payload untuk di-broadcast,
+// nil jika pesan tidak perlu dikirim. Aksinya sudah berhasil, jadi error cukup dicatat.
+func (u *chatUsecase) systemMessage(groupId uint, info dto.SystemInfo) []byte {
+	group, err := u.repo.GetGroup(groupId)
+	if err != nil {
+		log.Printf("pesan sistem grup %d : %v", groupId, err)
+		return nil
+	}
+	if group.IsAnnouncement && isJoinLeave(info.Action) {
+		return nil
+	}
+
+	usernames, err := u.repo.GetUsernames(append([]uint{info.ActorId}, info.TargetIds...))
+	if err != nil {
+		log.Printf("pesan sistem grup %d : %v", groupId, err)
+		return nil
+	}
+
+	chat, err := u.repo.CreateSystemChat(groupId, systemText(&info, usernames), &info)
+	if err != nil {
+		log.Printf("pesan sistem grup %d : %v", groupId, err)
+		return nil
+	}
+
+	response, _ := json.Marshal(&chat)
+	return response
+}
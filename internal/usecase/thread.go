@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"api_chat_ws/dto"
+	"api_chat_ws/helper/utils"
+	"api_chat_ws/model"
+)
+
+const maxThreadList = 50
+
+// chatTopic mengembalikan 0 untuk topic umum
+func chatTopic(chat *model.Chat) uint {
+	if chat.TopicID == nil {
+		return 0
+	}
+	return *chat.TopicID
+}
+
+// validateThread memastikan induk thread ada di grup dan topic yang sama dan bukan balasan thread lain
+func (u *chatUsecase) validateThread(req *dto.CreateChatReq) error {
+	if req.ThreadId == nil || *req.ThreadId == 0 {
+		return nil
+	}
+
+	root, err := u.repo.GetChat(*req.ThreadId)
+	if err == utils.ErrChatNotFound {
+		return utils.ErrInvalidThread
+	}
+	if err != nil {
+		return err
+	}
+	if root.GroupID != req.GroupId || root.ThreadRootID != nil || root.Type != model.ChatTypeMessage || root.DeletedAt != nil {
+		return utils.ErrInvalidThread
+	}
+
+	var topicId uint
+	if req.TopicId != nil {
+		topicId = *req.TopicId
+	}
+	if chatTopic(root) != topicId {
+		return utils.ErrInvalidThread
+	}
+
+	return nil
+}
+
+// GetThread mengembalikan pesan induk dan satu halaman balasan, lalu menandai balasan thread sudah dibaca
+func (u *chatUsecase) GetThread(req *dto.MessagePageReq) (*dto.ThreadPage, error) {
+	chat, err := u.repo.GetChat(*req.ThreadId)
+	if err == utils.ErrChatNotFound {
+		return nil, utils.ErrInvalidThread
+	}
+	if err != nil {
+		return nil, err
+	}
+	if chat.GroupID != req.GroupId || chat.ThreadRootID != nil {
+		return nil, utils.ErrInvalidThread
+	}
+
+	root, err := u.repo.GetChatResponse(chat.ID, req.MemberId)
+	if err != nil {
+		return nil, err
+	}
+
+	req.TopicId = nil
+	page, err := u.GetMessages(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.repo.MarkThreadRead(req.MemberId, root.ID); err != nil {
+		return nil, err
+	}
+
+	return &dto.ThreadPage{
+		Root:        root,
+		MessagePage: *page,
+	}, nil
+}
+
+// GetThreads menampilkan thread yang paling baru aktif beserta jumlah balasan yang belum dibaca member
+func (u *chatUsecase) GetThreads(memberId, groupId uint) ([]dto.ResponseChat, error) {
+	roots, err := u.repo.GetThreadRoots(groupId, memberId, maxThreadList)
+	if err != nil {
+		return nil, err
+	}
+	if len(roots) == 0 {
+		return roots, nil
+	}
+
+	rootIds := make([]uint, 0, len(roots))
+	for _, root := range roots {
+		rootIds = append(rootIds, root.ID)
+	}
+	unread, err := u.repo.GetThreadUnread(memberId, rootIds)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range roots {
+		count := unread[roots[i].ID]
+		roots[i].Thread.UnreadCount = &count
+	}
+
+	return roots, nil
+}
+
+// GetThreadInfo dipakai untuk memberi tahu client bahwa ringkasan thread berubah
+func (u *chatUsecase) GetThreadInfo(rootId uint) (*dto.ThreadInfo, error) {
+	root, err := u.repo.GetChatResponse(rootId, 0)
+	if err != nil {
+		return nil, err
+	}
+	return root.Thread, nil
+}
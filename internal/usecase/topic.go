@@ -0,0 +1,152 @@
+package usecase
+
+import (
+	"api_chat_ws/dto"
+	"api_chat_ws/helper/utils"
+	"api_chat_ws/model"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	maxTopicNameLength = 64
+	generalTopicName   = "Umum"
+)
+
+func toTopicResponse(topic *model.Topic, unread int64) dto.TopicResponse {
+	return dto.TopicResponse{
+		TopicId:     topic.ID,
+		GroupId:     topic.GroupID,
+		Name:        topic.Name,
+		CreatedBy:   topic.CreatedBy,
+		CreatedAt:   &topic.CreatedAt,
+		UnreadCount: unread,
+	}
+}
+
+func normalizeTopicName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxTopicNameLength {
+		return "", utils.ErrInvalidTopicName
+	}
+	return name, nil
+}
+
+// GetTopics selalu diawali topic umum (id 0) untuk pesan yang tidak masuk topic mana pun
+func (u *chatUsecase) GetTopics(memberId, groupId uint) ([]dto.TopicResponse, error) {
+	topics, err := u.repo.GetTopics(groupId)
+	if err != nil {
+		return nil, err
+	}
+
+	unread, err := u.repo.GetTopicUnread(memberId)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.TopicResponse, 0, len(topics)+1)
+	response = append(response, dto.TopicResponse{
+		GroupId:     groupId,
+		Name:        generalTopicName,
+		UnreadCount: unread[0],
+	})
+	for i := range topics {
+		response = append(response, toTopicResponse(&topics[i], unread[topics[i].ID]))
+	}
+
+	return response, nil
+}
+
+func (u *chatUsecase) CreateTopic(req *dto.TopicReq) (*dto.TopicResponse, error) {
+	if err := u.ensureManageable(req.GroupId); err != nil {
+		return nil, err
+	}
+
+	name, err := normalizeTopicName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := u.checkPermission(req.ActorId, model.PermCreateTopics)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := u.ensureCanPost(member); err != nil {
+		return nil, err
+	}
+
+	topic := model.Topic{
+		GroupID:   req.GroupId,
+		Name:      name,
+		CreatedBy: member.UserID,
+	}
+	if err := u.repo.CreateTopic(&topic); err != nil {
+		return nil, err
+	}
+
+	u.audit(req.GroupId, member.UserID, model.AuditTopicCreate, nil, nil, map[string]interface{}{"topic_id": topic.ID, "name": name})
+
+	response := toTopicResponse(&topic, 0)
+	return &response, nil
+}
+
+// RenameTopic boleh dilakukan pembuat topic, atau member lain yang punya permission manage_topics
+func (u *chatUsecase) RenameTopic(req *dto.TopicReq) (*dto.TopicResponse, error) {
+	if err := u.ensureManageable(req.GroupId); err != nil {
+		return nil, err
+	}
+
+	name, err := normalizeTopicName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	topic, err := u.repo.GetTopic(req.TopicId)
+	if err != nil {
+		return nil, err
+	}
+	if topic.GroupID != req.GroupId {
+		return nil, utils.ErrTopicNotFound
+	}
+
+	member, err := u.repo.GetMember(req.ActorId)
+	if err != nil {
+		return nil, err
+	}
+	if topic.CreatedBy != member.UserID {
+		allowed, err := u.hasPermission(member, model.PermManageTopics)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, utils.ErrForbidden
+		}
+	}
+
+	if err := u.repo.RenameTopic(topic.ID, name); err != nil {
+		return nil, err
+	}
+
+	u.audit(req.GroupId, member.UserID, model.AuditTopicRename, nil, map[string]interface{}{"topic_id": topic.ID, "name": topic.Name}, map[string]interface{}{"topic_id": topic.ID, "name": name})
+
+	topic.Name = name
+	response := toTopicResponse(topic, 0)
+	return &response, nil
+}
+
+// ValidateTopic memastikan topic milik grup tersebut, nil dan 0 berarti topic umum
+func (u *chatUsecase) ValidateTopic(groupId uint, topicId *uint) error {
+	if topicId == nil || *topicId == 0 {
+		return nil
+	}
+
+	topic, err := u.repo.GetTopic(*topicId)
+	if err != nil {
+		return err
+	}
+	if topic.GroupID != groupId {
+		return utils.ErrTopicNotFound
+	}
+
+	return nil
+}
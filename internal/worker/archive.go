@@ -0,0 +1,17 @@
+package worker
+
+import (
+	"api_chat_ws/internal/usecase"
+	"log"
+)
+
+// PurgeDeletedGroups menghapus permanen grup yang sudah melewati masa tenggang restore
+func PurgeDeletedGroups(chatUsecase usecase.ChatUsecase) func() error {
+	return func() error {
+		purged, err := chatUsecase.PurgeDeletedGroups()
+		if purged > 0 {
+			log.Printf("purge grup : %d grup dihapus permanen", purged)
+		}
+		return err
+	}
+}
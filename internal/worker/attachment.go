@@ -0,0 +1,17 @@
+package worker
+
+import (
+	"api_chat_ws/internal/usecase"
+	"log"
+)
+
+// PurgeOrphanAttachments menghapus file lampiran yang tidak lagi dipakai pesan mana pun
+func PurgeOrphanAttachments(chatUsecase usecase.ChatUsecase) func() error {
+	return func() error {
+		purged, err := chatUsecase.PurgeOrphanAttachments()
+		if purged > 0 {
+			log.Printf("lampiran : %d file dibersihkan", purged)
+		}
+		return err
+	}
+}
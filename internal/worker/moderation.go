@@ -0,0 +1,21 @@
+package worker
+
+import (
+	"api_chat_ws/internal/usecase"
+	"api_chat_ws/ws"
+)
+
+// ExpireSanctions mencabut mute/ban yang sudah kedaluwarsa lalu memberi tahu user yang bersangkutan
+func ExpireSanctions(chatUsecase usecase.ChatUsecase, hub *ws.Hub) func() error {
+	return func() error {
+		expired, err := chatUsecase.ExpireSanctions()
+		if err != nil {
+			return err
+		}
+
+		for _, s := range expired {
+			hub.SendToUser(s.UserId, ws.NewEvent(ws.EventSanction, s))
+		}
+		return nil
+	}
+}
@@ -0,0 +1,17 @@
+package worker
+
+import (
+	"api_chat_ws/internal/usecase"
+	"log"
+)
+
+// PurgeRetention menghapus pesan yang sudah melewati masa retensi grupnya
+func PurgeRetention(chatUsecase usecase.ChatUsecase) func() error {
+	return func() error {
+		deleted, err := chatUsecase.PurgeExpiredChats()
+		if deleted > 0 {
+			log.Printf("retention : %d pesan dihapus", deleted)
+		}
+		return err
+	}
+}
@@ -0,0 +1,18 @@
+package worker
+
+import (
+	"log"
+	"time"
+)
+
+// Run menjalankan job setiap interval, error hanya dicatat supaya job berikutnya tetap jalan
+func Run(name string, interval time.Duration, job func() error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := job(); err != nil {
+			log.Printf("worker %s : %v", name, err)
+		}
+	}
+}
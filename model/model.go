@@ -2,6 +2,8 @@ package model
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type User struct {
@@ -14,33 +16,307 @@ type User struct {
 }
 
 // chat
+const (
+	GroupTypeGroup  = "group"
+	GroupTypeDirect = "direct"
+)
+
 type ChatGroup struct {
 	ID          uint   `gorm:"primaryKey"`
 	Name        string `gorm:"not null"`
 	Description string
+	Type        string  `gorm:"not null;default:group"`
+	DirectKey   *string `gorm:"uniqueIndex;size:64"` // "<userA>:<userB>" khusus chat pribadi
+	// grup pengumuman: hanya admin ke atas yang bisa mengirim pesan
+	IsAnnouncement bool `gorm:"default:false"`
+	// pembuat grup, dipakai untuk kuota jumlah grup per user
+	CreatedBy uint `gorm:"index"`
+	// prefix key file di storage, kosong berarti belum ada gambar
+	AvatarKey string `gorm:"size:128"`
+	BannerKey string `gorm:"size:128"`
+	// jeda minimal antar pesan per member, 0 berarti slow mode mati
+	SlowModeSeconds int `gorm:"default:0"`
+	// penulis asli ikut ditampilkan saat pesan dari grup ini diteruskan ke grup lain
+	ForwardAuthor bool `gorm:"default:true"`
+	// batas lampiran per grup, 0 dan kosong berarti memakai batas server
+	AttachmentMaxMB int    `gorm:"default:0"`
+	AttachmentTypes string `gorm:"size:255"` // daftar MIME type dipisah koma, boleh "image/*"
+	// nil memakai default server, 0 berarti pesan disimpan selamanya
+	RetentionDays *int
+	// legal hold menangguhkan penghapusan pesan apa pun retensinya
+	LegalHold bool `gorm:"default:false"`
+	// grup yang diarsipkan hanya bisa dibaca dan tidak muncul di inbox
+	ArchivedAt *time.Time
+	// soft delete, owner masih bisa restore selama masa tenggang
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
 	LastMessage string
 	UnreadCount int
 	Members     []GroupMember `gorm:"foreignKey:GroupID;constraint:OnDelete:CASCADE"`
 	Chats       []Chat        `gorm:"foreignKey:GroupID;constraint:OnDelete:CASCADE"`
 }
 
+// role diurutkan dari yang paling tinggi: owner > admin > moderator > member
+const (
+	RoleOwner     = "owner"
+	RoleAdmin     = "admin"
+	RoleModerator = "moderator"
+	RoleMember    = "member"
+)
+
+var roleRank = map[string]int{
+	RoleMember:    1,
+	RoleModerator: 2,
+	RoleAdmin:     3,
+	RoleOwner:     4,
+}
+
+// RoleRank mengembalikan 0 untuk role yang tidak dikenal
+func RoleRank(role string) int {
+	return roleRank[role]
+}
+
+func IsValidRole(role string) bool {
+	_, ok := roleRank[role]
+	return ok
+}
+
 type GroupMember struct {
 	ID        uint      `gorm:"primaryKey"`
 	UserID    uint      `gorm:"index"`
 	User      User      `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
 	GroupID   uint      `gorm:"index"`
 	Role      string    `gorm:"not null"`
+	Nickname  string    `gorm:"size:64"` // nama tampilan khusus di grup ini, kosong berarti pakai username
+	ChatGroup ChatGroup `gorm:"foreignKey:GroupID;constraint:OnDelete:CASCADE"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+func (m *GroupMember) DisplayName() string {
+	if m.Nickname != "" {
+		return m.Nickname
+	}
+	return m.User.Username
+}
+
+// permission per grup dan role, owner selalu punya semua permission
+const (
+	PermSendMessage     = "send_message"
+	PermAddMembers      = "add_members"
+	PermEditInfo        = "edit_info"
+	PermPinMessages     = "pin_messages"
+	PermMentionEveryone = "mention_everyone"
+	PermSendAttachments = "send_attachments"
+	PermManageNicknames = "manage_nicknames"
+	PermCreateTopics    = "create_topics"
+	PermManageTopics    = "manage_topics"
+)
+
+var Permissions = []string{
+	PermSendMessage,
+	PermAddMembers,
+	PermEditInfo,
+	PermPinMessages,
+	PermMentionEveryone,
+	PermSendAttachments,
+	PermManageNicknames,
+	PermCreateTopics,
+	PermManageTopics,
+}
+
+func IsValidPermission(perm string) bool {
+	for _, p := range Permissions {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultPermission dipakai selama grup belum mengatur permission untuk role tersebut
+func DefaultPermission(role, perm string) bool {
+	switch role {
+	case RoleOwner, RoleAdmin:
+		return true
+	case RoleModerator:
+		return perm != PermAddMembers && perm != PermEditInfo
+	case RoleMember:
+		return perm == PermSendMessage || perm == PermSendAttachments || perm == PermCreateTopics
+	}
+	return false
+}
+
+type GroupPermission struct {
+	ID         uint      `gorm:"primaryKey"`
+	GroupID    uint      `gorm:"uniqueIndex:idx_group_role_perm"`
+	ChatGroup  ChatGroup `gorm:"foreignKey:GroupID;constraint:OnDelete:CASCADE"`
+	Role       string    `gorm:"uniqueIndex:idx_group_role_perm;size:20"`
+	Permission string    `gorm:"uniqueIndex:idx_group_role_perm;size:32"`
+	Allowed    bool
+}
+
+// moderasi
+const (
+	SanctionMute = "mute"
+	SanctionBan  = "ban"
+)
+
+// GroupSanction disimpan per user karena baris member dihapus saat di-ban.
+// Sanksi aktif jika LiftedAt kosong dan ExpiresAt belum lewat (nil berarti permanen).
+type GroupSanction struct {
+	ID        uint      `gorm:"primaryKey"`
+	GroupID   uint      `gorm:"index:idx_sanction_group_user"`
+	ChatGroup ChatGroup `gorm:"foreignKey:GroupID;constraint:OnDelete:CASCADE"`
+	UserID    uint      `gorm:"index:idx_sanction_group_user"`
+	Type      string    `gorm:"size:10;not null"`
+	Reason    string
+	IssuedBy  uint
+	ExpiresAt *time.Time `gorm:"index"`
+	LiftedAt  *time.Time
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// audit log, aktor dan target disimpan sebagai user id supaya tetap terbaca setelah member keluar
+const (
+	AuditGroupCreate       = "group.create"
+	AuditGroupUpdate       = "group.update"
+	AuditGroupDelete       = "group.delete"
+	AuditMemberAdd         = "member.add"
+	AuditMemberRemove      = "member.remove"
+	AuditMemberExit        = "member.exit"
+	AuditRoleUpdate        = "member.role"
+	AuditOwnershipTransfer = "group.transfer_ownership"
+	AuditPermissionUpdate  = "group.permission"
+	AuditMemberMute        = "member.mute"
+	AuditMemberUnmute      = "member.unmute"
+	AuditMemberBan         = "member.ban"
+	AuditMemberUnban       = "member.unban"
+	AuditChatDelete        = "chat.delete"
+	AuditRetentionUpdate   = "group.retention"
+	AuditGroupArchive      = "group.archive"
+	AuditGroupUnarchive    = "group.unarchive"
+	AuditGroupRestore      = "group.restore"
+	AuditNicknameUpdate    = "member.nickname"
+	AuditTopicCreate       = "topic.create"
+	AuditTopicRename       = "topic.rename"
+	AuditChatPin           = "chat.pin"
+	AuditChatUnpin         = "chat.unpin"
+)
+
+// GroupAudit sengaja tanpa foreign key ke grup supaya catatan penghapusan grup tidak ikut hilang
+type GroupAudit struct {
+	ID        uint      `gorm:"primaryKey"`
+	GroupID   uint      `gorm:"index:idx_audit_group_time"`
+	ActorID   uint      `gorm:"index"`
+	Action    string    `gorm:"size:32;index"`
+	TargetID  *uint     `gorm:"index"`
+	Before    string    `gorm:"type:text"`
+	After     string    `gorm:"type:text"`
+	CreatedAt time.Time `gorm:"autoCreateTime;index:idx_audit_group_time"`
+}
+
+// Topic membagi percakapan di dalam grup, pesan tanpa topic masuk ke topic umum
+type Topic struct {
+	ID        uint      `gorm:"primaryKey"`
+	GroupID   uint      `gorm:"uniqueIndex:idx_topic_group_name"`
 	ChatGroup ChatGroup `gorm:"foreignKey:GroupID;constraint:OnDelete:CASCADE"`
+	Name      string    `gorm:"uniqueIndex:idx_topic_group_name;size:64;not null"`
+	CreatedBy uint
+	CreatedAt time.Time `gorm:"autoCreateTime"`
 }
 
+// pesan sistem tidak punya pengirim, detail kejadiannya disimpan di Meta (json)
+const (
+	ChatTypeMessage = "message"
+	ChatTypeSystem  = "system"
+)
+
 type Chat struct {
-	ID            uint         `gorm:"primaryKey"`
-	GroupMemberID *uint        `gorm:"index"`
-	GroupMember   *GroupMember `gorm:"foreignKey:GroupMemberID;constraint:OnDelete:SET NULL"`
-	Message       string       `gorm:"not null"`
-	GroupID       uint         `gorm:"index"`
-	CreatedAt     time.Time    `gorm:"autoCreateTime"`
-	ReadStatus    []ChatRead   `gorm:"foreignKey:ChatId;constraint:OnDelete:CASCADE"`
+	ID             uint         `gorm:"primaryKey"`
+	GroupMemberID  *uint        `gorm:"index"`
+	GroupMember    *GroupMember `gorm:"foreignKey:GroupMemberID;constraint:OnDelete:SET NULL"`
+	Type           string       `gorm:"size:16;not null;default:message"`
+	Message        string       `gorm:"not null"`
+	Meta           string       `gorm:"type:text"`
+	GroupID        uint         `gorm:"index"`
+	TopicID        *uint        `gorm:"index"`
+	Topic          *Topic       `gorm:"foreignKey:TopicID;constraint:OnDelete:SET NULL"`
+	ParentID       *uint        `gorm:"index"` // tanpa foreign key supaya balasan tetap ada saat induknya terhapus
+	ThreadRootID   *uint        `gorm:"index"`
+	AlsoSent       bool         `gorm:"default:false"` // balasan thread yang juga tampil di timeline utama
+	ReplyCount     int          `gorm:"default:0"`     // khusus pesan induk thread
+	LastReplyAt    *time.Time   `gorm:"index"`
+	CreatedAt      time.Time    `gorm:"autoCreateTime"`
+	EditedAt       *time.Time   `gorm:"default:null"` // nil jika belum pernah diedit
+	DeletedAt      *time.Time   `gorm:"index"`        // tombstone, isi pesan dikosongkan tapi posisinya tetap di history
+	DeletedBy      *uint        // member id yang menghapus
+	PinnedAt       *time.Time   `gorm:"index"`
+	PinnedBy       *uint        // member id yang menyematkan
+	Forwarded      bool         `gorm:"default:false"`
+	ForwardChatID  *uint        `gorm:"index"` // asal pesan terusan, hanya diisi jika grup asal mengizinkan penulisnya ditampilkan
+	ForwardGroupID *uint        `gorm:"default:null"`
+	ForwardUserID  *uint        `gorm:"default:null"` // user id penulis asli
+	ReadStatus     []ChatRead   `gorm:"foreignKey:ChatId;constraint:OnDelete:CASCADE"`
+	Mentions       []Mention    `gorm:"foreignKey:ChatID;constraint:OnDelete:CASCADE"`
+	Attachments    []Attachment `gorm:"foreignKey:ChatID;constraint:OnDelete:SET NULL"`
+}
+
+// Attachment diunggah lebih dulu tanpa ChatID lalu ditautkan saat pesan dikirim.
+// Lampiran yang tidak punya pesan dibersihkan worker beserta file-nya.
+type Attachment struct {
+	ID          uint         `gorm:"primaryKey"`
+	GroupID     uint         `gorm:"index"` // tanpa foreign key supaya file tetap bisa dibersihkan setelah grup dihapus
+	ChatID      *uint        `gorm:"index"`
+	MemberID    *uint        `gorm:"index"` // pengunggah
+	Member      *GroupMember `gorm:"foreignKey:MemberID;constraint:OnDelete:SET NULL"`
+	StorageKey  string       `gorm:"size:128;not null"`
+	Name        string       `gorm:"size:255"`
+	ContentType string       `gorm:"size:100"`
+	Size        int64
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+}
+
+// jenis mention, satu member hanya dicatat sekali per pesan dengan prioritas mention langsung
+const (
+	MentionUser     = "user"
+	MentionHere     = "here"
+	MentionEveryone = "everyone"
+)
+
+type Mention struct {
+	ID       uint        `gorm:"primaryKey"`
+	ChatID   uint        `gorm:"uniqueIndex:idx_mention"`
+	MemberID uint        `gorm:"uniqueIndex:idx_mention;index"`
+	Member   GroupMember `gorm:"foreignKey:MemberID;constraint:OnDelete:CASCADE"`
+	Kind     string      `gorm:"size:10;not null"`
+}
+
+type Reaction struct {
+	ID        uint        `gorm:"primaryKey"`
+	ChatID    uint        `gorm:"uniqueIndex:idx_reaction"`
+	Chat      Chat        `gorm:"foreignKey:ChatID;constraint:OnDelete:CASCADE"`
+	MemberID  uint        `gorm:"uniqueIndex:idx_reaction;index"`
+	Member    GroupMember `gorm:"foreignKey:MemberID;constraint:OnDelete:CASCADE"`
+	Emoji     string      `gorm:"uniqueIndex:idx_reaction;size:32;not null"`
+	CreatedAt time.Time   `gorm:"autoCreateTime"`
+}
+
+// ChatRevision menyimpan isi pesan sebelum diedit, CreatedAt adalah waktu isi tersebut diganti
+type ChatRevision struct {
+	ID        uint      `gorm:"primaryKey"`
+	ChatID    uint      `gorm:"index"`
+	Chat      Chat      `gorm:"foreignKey:ChatID;constraint:OnDelete:CASCADE"`
+	Message   string    `gorm:"not null"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// HiddenChat adalah pesan yang disembunyikan satu member saja ("hapus untuk saya")
+type HiddenChat struct {
+	ID        uint        `gorm:"primaryKey"`
+	ChatID    uint        `gorm:"uniqueIndex:idx_hidden_chat"`
+	Chat      Chat        `gorm:"foreignKey:ChatID;constraint:OnDelete:CASCADE"`
+	MemberID  uint        `gorm:"uniqueIndex:idx_hidden_chat;index"`
+	Member    GroupMember `gorm:"foreignKey:MemberID;constraint:OnDelete:CASCADE"`
+	CreatedAt time.Time   `gorm:"autoCreateTime"`
 }
 
 type ChatRead struct {
@@ -14,7 +14,7 @@ const (
 	writeWait      = 10 * time.Second
 	pongWait       = 60 * time.Second
 	pingPeriod     = (pongWait * 9) / 10
-	maxMessageSize = 512
+	maxMessageSize = 64 * 1024 // panjang isi pesan divalidasi lagi di usecase
 )
 
 func (c *Client) ReadPump(hub *Hub, usecase usecase.ChatUsecase) {
@@ -29,86 +29,193 @@ func (c *Client) ReadPump(hub *Hub, usecase usecase.ChatUsecase) {
 		return nil
 	})
 
-	chats, err := usecase.LoadGroupChat(c.GroupID)
+	// halaman lama diambil client lewat GET /chat/group/{groupId}/messages?before=
+	chats, err := usecase.LoadGroupChat(c.MemberId, c.GroupID, c.TopicID)
 	if err == nil {
-		c.Send <- chats
-		_ = usecase.UpdateStatusChat(c.MemberId)
+		c.sendEvent(EventHistory, json.RawMessage(chats))
+		_ = usecase.UpdateStatusChat(c.MemberId, c.TopicID)
 	}
 
-	members, err := usecase.GetMembers(c.GroupID)
-	if err == nil {
-		membersOnline := hub.GetClientsByGroupID(c.GroupID)
-
-		membersStatus := make([]dto.MemberStatus, 0, len(members))
-		onlineMap := make(map[uint]bool)
-
-		for _, m := range membersOnline {
-			onlineMap[m.MemberId] = true
+	for {
+		_, msg, err := c.Conn.ReadMessage()
+		if err != nil {
+			break
 		}
 
-		for _, member := range members {
-			isOnline := onlineMap[member] // akan false jika tidak ada
-			membersStatus = append(membersStatus, dto.MemberStatus{
-				MemberId: member,
-				Status:   isOnline,
-			})
+		var incoming dto.IncomingMessage
+		if err := json.Unmarshal(msg, &incoming); err != nil {
+			fmt.Print("error json")
+			continue
 		}
-
-		for {
-
-			_, msg, err := c.Conn.ReadMessage()
+		switch incoming.Action {
+		case "create":
+			status, err := membersStatus(hub, usecase, c.GroupID)
 			if err != nil {
-				break
+				c.sendError(incoming.Action, err)
+				continue
 			}
 
-			type IncomingMessage struct {
-				Action  string `json:"action"`
-				Content string `json:"content"`
-				ID      uint   `json:"id"`
+			// tanpa topic_id pesan masuk ke topic yang sedang dibuka client
+			topicId := incoming.TopicId
+			if topicId == nil {
+				topicId = c.TopicID
+			}
+			if topicId == nil {
+				topicId = new(uint)
 			}
 
-			var incoming IncomingMessage
-			if err := json.Unmarshal(msg, &incoming); err != nil {
-				fmt.Print("error json")
+			response, mention, err := usecase.CreateChat(&dto.CreateChatReq{
+				GroupId:       c.GroupID,
+				MemberId:      c.MemberId,
+				Message:       incoming.Content,
+				TopicId:       topicId,
+				ParentId:      incoming.ParentId,
+				ThreadId:      incoming.ThreadId,
+				AlsoSend:      incoming.AlsoSend,
+				AttachmentIds: incoming.AttachmentIds,
+			}, status)
+			if err != nil {
+				c.sendError(incoming.Action, err)
 				continue
 			}
-			switch incoming.Action {
-			case "create":
-				response, err := usecase.CreateChat(c.MemberId, c.GroupID, incoming.Content, membersStatus)
-				if err != nil {
-					continue
-				}
 
-				hub.Broadcast <- BroadcastMessage{
-					GroupID: c.GroupID,
-					Message: response,
-				}
-			case "update":
-				response, err := usecase.UpdateChat(c.MemberId, c.GroupID, incoming.Content)
-				if err != nil {
-					continue
-				}
+			hub.Broadcast <- BroadcastMessage{
+				GroupID: c.GroupID,
+				TopicID: topicId,
+				Message: response,
+			}
 
-				hub.Broadcast <- BroadcastMessage{
-					GroupID: c.GroupID,
-					Message: response,
-				}
-			case "delete":
-				response, err := usecase.DeleteChat(c.MemberId, incoming.ID)
-				if err != nil {
-					continue
+			// mention dikirim langsung ke user walaupun sedang membuka grup lain
+			if mention != nil {
+				event := NewEvent(EventMention, mention)
+				for _, userId := range mention.UserIds {
+					hub.SendToUser(userId, event)
 				}
+			}
 
-				hub.Broadcast <- BroadcastMessage{
-					GroupID: c.GroupID,
-					Message: response,
+			// ringkasan thread di pesan induk ikut diperbarui di semua client
+			if incoming.ThreadId != nil && *incoming.ThreadId != 0 {
+				if thread, err := usecase.GetThreadInfo(*incoming.ThreadId); err == nil {
+					hub.Broadcast <- BroadcastMessage{
+						GroupID: c.GroupID,
+						TopicID: topicId,
+						Message: NewEvent(EventThread, dto.ThreadEvent{RootId: *incoming.ThreadId, Thread: thread}),
+					}
 				}
 			}
+		case "update":
+			response, err := usecase.UpdateChat(incoming.ID, c.MemberId, incoming.Content)
+			if err != nil {
+				c.sendError(incoming.Action, err)
+				continue
+			}
+
+			hub.Broadcast <- BroadcastMessage{
+				GroupID: c.GroupID,
+				Message: response,
+			}
+		case "forward":
+			status, err := membersStatus(hub, usecase, incoming.TargetGroupId)
+			if err != nil {
+				c.sendError(incoming.Action, err)
+				continue
+			}
 
+			// topic_id menunjuk topic di grup tujuan, tanpa topic masuk ke topic umum
+			topicId := incoming.TopicId
+			if topicId == nil {
+				topicId = new(uint)
+			}
+
+			response, err := usecase.ForwardChat(&dto.ForwardReq{
+				UserId:        c.UserID,
+				GroupId:       c.GroupID,
+				ChatId:        incoming.ID,
+				TargetGroupId: incoming.TargetGroupId,
+				TopicId:       topicId,
+			}, status)
+			if err != nil {
+				c.sendError(incoming.Action, err)
+				continue
+			}
+
+			hub.Broadcast <- BroadcastMessage{
+				GroupID: incoming.TargetGroupId,
+				TopicID: topicId,
+				Message: response,
+			}
+		case "react", "unreact":
+			event, err := usecase.React(&dto.ReactionReq{
+				MemberId: c.MemberId,
+				GroupId:  c.GroupID,
+				ChatId:   incoming.ID,
+				Emoji:    incoming.Emoji,
+				Add:      incoming.Action == "react",
+			})
+			if err != nil {
+				c.sendError(incoming.Action, err)
+				continue
+			}
+
+			hub.Broadcast <- BroadcastMessage{
+				GroupID: c.GroupID,
+				Message: NewEvent(EventReaction, event),
+			}
+		case "delete", "delete_for_me":
+			forMe := incoming.Action == "delete_for_me"
+			response, err := usecase.DeleteChat(&dto.DeleteChatReq{
+				MemberId: c.MemberId,
+				GroupId:  c.GroupID,
+				ChatId:   incoming.ID,
+				ForMe:    forMe,
+			})
+			if err != nil {
+				c.sendError(incoming.Action, err)
+				continue
+			}
+
+			// hapus untuk saya hanya dikirim ke koneksi milik user ini
+			if forMe {
+				hub.SendToUser(c.UserID, NewEvent(EventHidden, dto.HiddenEvent{GroupId: c.GroupID, ChatId: incoming.ID}))
+				continue
+			}
+			hub.Broadcast <- BroadcastMessage{
+				GroupID: c.GroupID,
+				Message: response,
+			}
 		}
+	}
+}
+
+// membersStatus dihitung setiap kirim pesan supaya member yang baru ditambahkan ikut tercatat
+func membersStatus(hub *Hub, usecase usecase.ChatUsecase, groupId uint) ([]dto.MemberStatus, error) {
+	members, err := usecase.GetMembers(groupId)
+	if err != nil {
+		return nil, err
+	}
 
+	onlineMap := make(map[uint]bool)
+	for _, m := range hub.GetClientsByGroupID(groupId) {
+		onlineMap[m.MemberId] = true
 	}
 
+	statuses := make([]dto.MemberStatus, 0, len(members))
+	for _, member := range members {
+		statuses = append(statuses, dto.MemberStatus{
+			MemberId: member,
+			Status:   onlineMap[member], // akan false jika tidak ada
+		})
+	}
+
+	return statuses, nil
+}
+
+// closeAfterFlush memberi WritePump waktu mengirim pesan terakhir sebelum koneksi ditutup
+func (c *Client) closeAfterFlush() {
+	time.AfterFunc(time.Second, func() {
+		c.Conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, ""), time.Now().Add(writeWait))
+		c.Conn.Close()
+	})
 }
 
 func (c *Client) WritePump() {
@@ -0,0 +1,61 @@
+package ws
+
+import (
+	"api_chat_ws/dto"
+	"api_chat_ws/helper/utils"
+	"encoding/json"
+	"errors"
+)
+
+const (
+	EventError       = "error"
+	EventForbidden   = "forbidden"
+	EventSanction    = "sanction"
+	EventGroupClosed = "group_closed"
+	EventRateLimited = "rate_limited"
+	EventTopic       = "topic"
+	EventHistory     = "history"
+	EventThread      = "thread"
+	EventReaction    = "reaction"
+	EventHidden      = "hidden"
+	EventMention     = "mention"
+	EventPin         = "pin"
+	EventUnpin       = "unpin"
+)
+
+func NewEvent(event string, data interface{}) []byte {
+	payload, _ := json.Marshal(dto.WsEvent{
+		Event: event,
+		Data:  data,
+	})
+	return payload
+}
+
+// sendEvent tidak menunggu jika buffer client penuh, dan tidak mengirim jika hub sudah menutup client
+func (c *Client) sendEvent(event string, data interface{}) {
+	c.trySend(NewEvent(event, data))
+}
+
+func (c *Client) sendError(action string, err error) {
+	event := EventError
+	switch {
+	case errors.Is(err, utils.ErrForbidden),
+		errors.Is(err, utils.ErrAnnouncementOnly),
+		errors.Is(err, utils.ErrMuted),
+		errors.Is(err, utils.ErrGroupArchived),
+		errors.Is(err, utils.ErrMentionEveryone):
+		event = EventForbidden
+	}
+
+	payload := dto.ErrorEvent{
+		Action:  action,
+		Message: err.Error(),
+	}
+	var rateErr *utils.RateLimitError
+	if errors.As(err, &rateErr) {
+		event = EventRateLimited
+		payload.RetryAfterMs = rateErr.RetryAfter.Milliseconds()
+	}
+
+	c.sendEvent(event, payload)
+}
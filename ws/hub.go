@@ -9,17 +9,53 @@ import (
 
 type Client struct {
 	MemberId uint
+	UserID   uint
 	GroupID  uint
+	TopicID  *uint // nil berarti menerima semua topic, 0 berarti topic umum
 	Conn     *websocket.Conn
 	Send     chan []byte
+
+	// mu menjaga supaya tidak ada yang mengirim ke Send setelah channel-nya ditutup
+	mu     sync.Mutex
+	closed bool
 }
 
 type BroadcastMessage struct {
 	GroupID uint
+	TopicID *uint // nil berarti dikirim ke semua client di grup
 	Action  string
 	Message []byte
 }
 
+func (c *Client) wantsTopic(topicId *uint) bool {
+	return c.TopicID == nil || topicId == nil || *c.TopicID == *topicId
+}
+
+// trySend tidak menunggu jika buffer client penuh, false jika pesan tidak terkirim atau client sudah ditutup
+func (c *Client) trySend(message []byte) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return false
+	}
+	select {
+	case c.Send <- message:
+		return true
+	default:
+		return false
+	}
+}
+
+// closeSend aman dipanggil lebih dari sekali
+func (c *Client) closeSend() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.closed {
+		c.closed = true
+		close(c.Send)
+	}
+}
+
 type Hub struct {
 	Groups     map[uint]map[uint]*Client
 	Register   chan *Client
@@ -51,28 +87,31 @@ func (h *Hub) Run() {
 		case client := <-h.Unregister:
 			h.mu.Lock()
 			if groupClients, ok := h.Groups[client.GroupID]; ok {
-				if _, ok := groupClients[client.MemberId]; ok {
+				// koneksi baru member yang sama tidak ikut dihapus
+				if groupClients[client.MemberId] == client {
 					delete(groupClients, client.MemberId)
-					close(client.Send)
 				}
 				if len(groupClients) == 0 {
 					delete(h.Groups, client.GroupID)
 				}
 			}
 			h.mu.Unlock()
+			client.closeSend()
 
 		case msg := <-h.Broadcast:
-			h.mu.RLock()
+			// client yang buffer-nya penuh dikeluarkan dari map, jadi butuh write lock
+			h.mu.Lock()
 			groupClients := h.Groups[msg.GroupID]
 			for _, client := range groupClients {
-				select {
-				case client.Send <- msg.Message:
-				default:
-					close(client.Send)
+				if !client.wantsTopic(msg.TopicID) {
+					continue
+				}
+				if !client.trySend(msg.Message) {
+					client.closeSend()
 					delete(groupClients, client.MemberId)
 				}
 			}
-			h.mu.RUnlock()
+			h.mu.Unlock()
 		}
 	}
 }
@@ -96,3 +135,52 @@ func (h *Hub) GetClientsByGroupID(groupID uint) []dto.MemberStatus {
 
 	return clients
 }
+
+// SendToUser mengirim pesan ke semua koneksi milik user, di grup mana pun dia terhubung
+func (h *Hub) SendToUser(userId uint, message []byte) {
+	h.SendToUserExcept(userId, 0, message)
+}
+
+// SendToUserExcept sama seperti SendToUser tapi melewati koneksi di grup exceptGroupId
+func (h *Hub) SendToUserExcept(userId, exceptGroupId uint, message []byte) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for groupId, groupClients := range h.Groups {
+		if groupId == exceptGroupId {
+			continue
+		}
+		for _, client := range groupClients {
+			if client.UserID != userId {
+				continue
+			}
+			client.trySend(message)
+		}
+	}
+}
+
+// Kick mengirim pesan terakhir lalu menutup koneksi user di grup tersebut,
+// ReadPump yang akan unregister client-nya
+func (h *Hub) Kick(groupId, userId uint, message []byte) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for _, client := range h.Groups[groupId] {
+		if client.UserID != userId {
+			continue
+		}
+		client.trySend(message)
+		client.closeAfterFlush()
+	}
+}
+
+// CloseGroup mengirim pesan terakhir lalu menutup semua koneksi di grup tersebut
+func (h *Hub) CloseGroup(groupId uint, message []byte) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for _, client := range h.Groups[groupId] {
+		client.trySend(message)
+		client.closeAfterFlush()
+	}
+}